@@ -0,0 +1,709 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
+)
+
+var (
+	applyFile   string
+	applyDryRun bool
+	applyYes    bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <manifest>",
+	Short: "Create or update group hierarchies from a YAML/JSON manifest",
+	Long: `Make GitLab and the local .ash metadata match a manifest describing groups,
+subgroups and projects.
+
+The manifest is compared with GitLab first and the plan is printed: what will
+be created, what will be updated (name, visibility, description, default
+branch) and which metadata files will be written. Nothing is ever deleted:
+subgroups and projects missing from the manifest are listed as [EXTRA] and
+left alone. Applying the same manifest twice changes nothing.
+
+Top-level groups are scaffolded in a folder named after the group in the
+working directory (or the group folder you are in). Projects are not cloned;
+run 'ash group sync' afterwards.
+
+Example manifest (course.yaml):
+
+  groups:
+    - name: CNTT2 - Spring 2025
+      visibility: private
+      subgroups:
+        - name: Session 1
+          projects:
+            - name: Lab1
+              description: Variables and loops
+              default_branch: main
+            - prefix: Exercise
+              count: 5`,
+	Example: `  ash apply -f course.yaml --dry-run
+  ash apply -f course.yaml
+  ash apply -f course.json --yes`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		m, err := loadManifest(applyFile)
+		if err != nil {
+			return err
+		}
+		api, err := newGitLabClient()
+		if err != nil {
+			return err
+		}
+
+		var plans []*applyGroupPlan
+		err = RunSpinner("Comparing manifest with GitLab", func() error {
+			plans, err = planApply(ctx, api, m)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		out := infoOut()
+		var sum applySummary
+		for _, p := range plans {
+			p.Print(out)
+			sum.add(p)
+		}
+		fmt.Fprintf(out, "\n%d to create, %d to update, %d metadata file(s) to write, %d unchanged.\n", sum.Create, sum.Update, sum.Write, sum.Unchanged)
+
+		if sum.Create+sum.Update+sum.Write == 0 {
+			fmt.Fprintf(out, "%s[OK] Everything matches the manifest.%s\n", Green, Reset)
+			return nil
+		}
+		if applyDryRun {
+			fmt.Fprintf(out, "\n%s[DRY-RUN] No changes made.%s\n", Yellow, Reset)
+			return nil
+		}
+		if err := confirmApply(); err != nil {
+			return err
+		}
+
+		var results []TaskResult
+		createdTop := false
+		for _, p := range plans {
+			createdTop = createdTop || p.Remote == nil
+			applyGroup(ctx, api, p, 0, &results)
+		}
+		if createdTop && ctx.Err() == nil {
+			// Keep `ash group list` / `group clone` aware of the new groups.
+			if err := fetchAndSaveGroups(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "%s[WARN] Resync config failed: %v%s\n", Yellow, err, Reset)
+			}
+		}
+		PrintResults(results)
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		failed := 0
+		for _, r := range results {
+			if r.Status == "ERR" {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d change(s) failed; fix the cause and run apply again", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "Manifest file (YAML or JSON, - for stdin)")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Print the plan without changing anything")
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "Apply without asking for confirmation")
+	applyCmd.MarkFlagRequired("file")
+}
+
+// confirmApply asks before changing anything, unless --yes was given.
+func confirmApply() error {
+	if applyYes {
+		return nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return fmt.Errorf("refusing to apply without confirmation: re-run with --yes")
+	}
+	confirmed := false
+	err := huh.NewConfirm().
+		Title("Apply these changes?").
+		Affirmative("Apply").
+		Negative("Cancel").
+		Value(&confirmed).
+		Run()
+	if err != nil || !confirmed {
+		return fmt.Errorf("apply cancelled, nothing changed")
+	}
+	return nil
+}
+
+// --- APPLY PLAN ---
+
+// applyGroupPlan is the plan for one group or subgroup of the manifest.
+type applyGroupPlan struct {
+	Spec  manifestGroup
+	Label string // "Course/Session 1", used in messages
+	Dir   string // local folder
+	Root  bool
+	// Visibility is the one the group gets: from the manifest, else the
+	// current one on GitLab, else the parent's (GitLab refuses a subgroup or
+	// project more visible than its group).
+	Visibility string
+	Remote     *glGroup // nil: will be created
+	Update     gitlab.UpdateGroupOptions
+	Changes    []string
+
+	// RenameFrom is the local folder to move to Dir when GitLab still has
+	// the old name and a folder by that name exists.
+	RenameFrom string
+
+	// known maps IDs to the folders recorded in the level's metadata.
+	known map[int64]string
+
+	Subgroups      []*applyGroupPlan
+	Projects       []*applyProjectPlan
+	ExtraSubgroups []glGroup // on GitLab, not in the manifest
+	ExtraProjects  []glProject
+
+	MetaStale bool // the level's .ash metadata must be (re)written
+}
+
+// applyProjectPlan is the plan for one project of the manifest.
+type applyProjectPlan struct {
+	Spec       manifestProject
+	Label      string
+	Remote     *glProject // nil: will be created
+	Update     gitlab.UpdateProjectOptions
+	Changes    []string
+	Folder     string // local folder, inside the level's
+	RenameFrom string // local folder to move along with a rename
+}
+
+// planApply compares every top-level group of m with GitLab and the local metadata.
+func planApply(ctx context.Context, api gitlab.API, m *manifest) ([]*applyGroupPlan, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	ws, _ := locateWorkspace(wd)
+
+	var plans []*applyGroupPlan
+	for _, spec := range m.Groups {
+		remote, err := api.GetGroupByPath(ctx, spec.slug())
+		if legacy := legacySlugify(spec.Name); gitlab.IsNotFound(err) && spec.Path == "" && legacy != spec.slug() && legacy != "" {
+			remote, err = api.GetGroupByPath(ctx, legacy) // created by an older ash
+		}
+		if gitlab.IsNotFound(err) {
+			remote, err = nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("look up group %s: %w", spec.slug(), err)
+		}
+
+		// Reuse the group folder we are standing in; otherwise <wd>/<name>.
+		dir := filepath.Join(wd, defaultFolder(spec.Name, spec.slug()))
+		if ws != nil && ws.GroupRoot != "" {
+			var meta rootGroupMeta
+			if readGroupMeta(filepath.Join(ws.GroupRoot, ".ash", "group.json"), &meta) == nil &&
+				(strings.EqualFold(meta.Group.Path, spec.slug()) || (remote != nil && meta.Group.ID == remote.ID)) {
+				dir = ws.GroupRoot
+			}
+		}
+
+		p, err := planApplyGroup(ctx, api, spec, remote, spec.Name, dir, "", "public", true)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+// planApplyGroup plans one level. remote is the matching GitLab group (nil
+// if it does not exist yet); oldDir is its current folder, which may differ
+// from dir; parentVis is the visibility inherited when the manifest sets none.
+func planApplyGroup(ctx context.Context, api gitlab.API, spec manifestGroup, remote *glGroup, label, dir, oldDir, parentVis string, root bool) (*applyGroupPlan, error) {
+	p := &applyGroupPlan{Spec: spec, Label: label, Dir: dir, Root: root, Remote: remote}
+	switch {
+	case spec.Visibility != "":
+		p.Visibility = spec.Visibility
+	case remote != nil && remote.Visibility != "":
+		p.Visibility = remote.Visibility
+	default:
+		p.Visibility = parentVis
+	}
+	// cur is the folder as it is now. oldDir lies in the parent's current
+	// folder, which apply moves to the parent's new one before this level,
+	// so RenameFrom is recorded where the old folder will be by then.
+	cur := dir
+	if oldDir != "" {
+		cur = oldDir
+	}
+	if now := filepath.Join(filepath.Dir(cur), filepath.Base(dir)); cur != now && fileExists(cur) && !fileExists(now) {
+		p.RenameFrom = filepath.Join(filepath.Dir(dir), filepath.Base(cur))
+	} else {
+		cur = now
+	}
+	p.known = knownFolders(cur)
+
+	var sgs []glGroup
+	var prjs []glProject
+	if remote != nil {
+		if spec.Name != remote.Name {
+			p.Update.Name = spec.Name
+			p.Changes = append(p.Changes, fmt.Sprintf("name %q -> %q", remote.Name, spec.Name))
+		}
+		if spec.Visibility != "" && spec.Visibility != remote.Visibility {
+			p.Update.Visibility = spec.Visibility
+			p.Changes = append(p.Changes, fmt.Sprintf("visibility %s -> %s", orNone(remote.Visibility), spec.Visibility))
+		}
+		if spec.Description != "" && spec.Description != remote.Description {
+			p.Update.Description = spec.Description
+			p.Changes = append(p.Changes, "description")
+		}
+
+		var err error
+		if sgs, err = api.ListSubgroups(ctx, remote.ID); err != nil {
+			return nil, fmt.Errorf("list subgroups of %s: %w", label, err)
+		}
+		if prjs, err = api.ListGroupProjects(ctx, remote.ID); err != nil {
+			return nil, fmt.Errorf("list projects of %s: %w", label, err)
+		}
+	}
+
+	// Subgroups (matched by path)
+	matched := make(map[int64]bool)
+	for _, sgSpec := range spec.Subgroups {
+		var sgRemote *glGroup
+		for i := range sgs {
+			if sgs[i].MarkedForDeletionOn == "" && sgSpec.matches(sgs[i].Path) {
+				sgRemote = &sgs[i]
+				matched[sgs[i].ID] = true
+				break
+			}
+		}
+		oldDir, folder := "", defaultFolder(sgSpec.Name, sgSpec.slug())
+		if sgRemote != nil {
+			old := p.folderOf(sgRemote.ID, sgRemote.Name, sgRemote.Path)
+			oldDir = filepath.Join(cur, old)
+			folder = followRename(old, sgRemote.Name, sgRemote.Path, sgSpec.Name, sgRemote.Path)
+		}
+		child, err := planApplyGroup(ctx, api, sgSpec, sgRemote, label+"/"+sgSpec.Name, filepath.Join(dir, folder), oldDir, p.Visibility, false)
+		if err != nil {
+			return nil, err
+		}
+		p.Subgroups = append(p.Subgroups, child)
+	}
+	for _, sg := range sgs {
+		if !matched[sg.ID] && sg.MarkedForDeletionOn == "" {
+			p.ExtraSubgroups = append(p.ExtraSubgroups, sg)
+		}
+	}
+
+	// Projects (matched by path)
+	for _, prjSpec := range spec.Projects {
+		pp := &applyProjectPlan{Spec: prjSpec, Label: label + "/" + prjSpec.Name, Folder: defaultFolder(prjSpec.Name, prjSpec.slug())}
+		for i := range prjs {
+			if prjSpec.matches(prjs[i].Path) {
+				pp.Remote = &prjs[i]
+				matched[prjs[i].ID] = true
+				break
+			}
+		}
+		if pp.Remote != nil {
+			if err := pp.diff(ctx, api); err != nil {
+				return nil, fmt.Errorf("inspect project %s: %w", pp.Label, err)
+			}
+			old := p.folderOf(pp.Remote.ID, pp.Remote.Name, pp.Remote.Path)
+			pp.Folder = followRename(old, pp.Remote.Name, pp.Remote.Path, prjSpec.Name, pp.Remote.Path)
+			if pp.Folder != old && fileExists(filepath.Join(cur, old)) && !fileExists(filepath.Join(cur, pp.Folder)) {
+				pp.RenameFrom = filepath.Join(dir, old)
+			}
+		}
+		p.Projects = append(p.Projects, pp)
+	}
+	for _, prj := range prjs {
+		if !matched[prj.ID] {
+			p.ExtraProjects = append(p.ExtraProjects, prj)
+		}
+	}
+
+	p.MetaStale = p.metaStale(cur)
+	return p, nil
+}
+
+// diff fills the changes needed to make the remote project match its spec.
+func (pp *applyProjectPlan) diff(ctx context.Context, api gitlab.API) error {
+	spec, remote := pp.Spec, pp.Remote
+	if (spec.Visibility != "" && remote.Visibility == "") || spec.DefaultBranch != "" {
+		// Not part of the simple project listing, which also reports the
+		// instance default branch for empty repositories
+		full, err := api.GetProject(ctx, remote.ID)
+		if err != nil {
+			return err
+		}
+		remote.Visibility = full.Visibility
+		remote.DefaultBranch = full.DefaultBranch
+		remote.EmptyRepo = full.EmptyRepo
+	}
+	if spec.Name != remote.Name {
+		pp.Update.Name = spec.Name
+		pp.Changes = append(pp.Changes, fmt.Sprintf("name %q -> %q", remote.Name, spec.Name))
+	}
+	if spec.Visibility != "" && spec.Visibility != remote.Visibility {
+		pp.Update.Visibility = spec.Visibility
+		pp.Changes = append(pp.Changes, fmt.Sprintf("visibility %s -> %s", orNone(remote.Visibility), spec.Visibility))
+	}
+	if spec.Description != "" && spec.Description != remote.Description {
+		pp.Update.Description = spec.Description
+		pp.Changes = append(pp.Changes, "description")
+	}
+	// An empty repository has no branch to make the default yet; GitLab
+	// rejects the update, so leave it until the first push.
+	empty := remote.EmptyRepo || remote.DefaultBranch == ""
+	if spec.DefaultBranch != "" && spec.DefaultBranch != remote.DefaultBranch && !empty {
+		pp.Update.DefaultBranch = spec.DefaultBranch
+		pp.Changes = append(pp.Changes, fmt.Sprintf("default branch %s -> %s", orNone(remote.DefaultBranch), spec.DefaultBranch))
+	}
+	return nil
+}
+
+// meta is the metadata the level will have once applied, from what is known
+// on GitLab. ok is false while part of it is still to be created.
+func (p *applyGroupPlan) meta() (meta levelMeta, ok bool) {
+	ok = p.Remote != nil
+	meta.Root = p.Root
+	if p.Remote != nil {
+		meta.Group = groupIdent{ID: p.Remote.ID, Path: p.Remote.Path, Name: p.Spec.Name}
+	}
+	meta.Subgroups = []subgroupIdent{}
+	for _, sg := range p.Subgroups {
+		if sg.Remote == nil {
+			ok = false
+			continue
+		}
+		meta.Subgroups = append(meta.Subgroups, subgroupIdent{ID: sg.Remote.ID, Path: sg.Remote.Path, Name: sg.Spec.Name, Dir: filepath.Base(sg.Dir)})
+	}
+	for _, sg := range p.ExtraSubgroups {
+		meta.Subgroups = append(meta.Subgroups, subgroupIdent{ID: sg.ID, Path: sg.Path, Name: sg.Name, Dir: p.folderOf(sg.ID, sg.Name, sg.Path)})
+	}
+	meta.Projects = []projectIdent{}
+	for _, pp := range p.Projects {
+		if pp.Remote == nil {
+			ok = false
+			continue
+		}
+		meta.Projects = append(meta.Projects, projectIdent{ID: pp.Remote.ID, Path: pp.Remote.Path, Name: pp.Spec.Name, Dir: pp.Folder})
+	}
+	for _, prj := range p.ExtraProjects {
+		meta.Projects = append(meta.Projects, projectIdent{ID: prj.ID, Path: prj.Path, Name: prj.Name, Dir: p.folderOf(prj.ID, prj.Name, prj.Path)})
+	}
+	return meta, ok
+}
+
+// folderOf is the folder of a subgroup or project of this level: the one
+// recorded in the metadata, else the default one.
+func (p *applyGroupPlan) folderOf(id int64, name, path string) string {
+	if d, ok := p.known[id]; ok {
+		return d
+	}
+	return defaultFolder(name, path)
+}
+
+// metaStale reports whether the metadata file of the level folder dir
+// differs from meta().
+func (p *applyGroupPlan) metaStale(dir string) bool {
+	want, ok := p.meta()
+	if !ok || p.RenameFrom != "" {
+		return true
+	}
+	have, err := readLevelMeta(dir)
+	if err != nil || have.Root != want.Root || have.Group != want.Group {
+		return true
+	}
+	return !sameSubgroups(have.Subgroups, want.Subgroups) || !sameProjects(have.Projects, want.Projects)
+}
+
+func sameSubgroups(a, b []subgroupIdent) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[subgroupIdent]bool, len(a))
+	for _, x := range a {
+		set[x] = true
+	}
+	for _, x := range b {
+		if !set[x] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameProjects(a, b []projectIdent) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[projectIdent]bool, len(a))
+	for _, x := range a {
+		set[x] = true
+	}
+	for _, x := range b {
+		if !set[x] {
+			return false
+		}
+	}
+	return true
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// --- APPLY PLAN OUTPUT ---
+
+type applySummary struct {
+	Create, Update, Write, Unchanged int
+}
+
+func (s *applySummary) add(p *applyGroupPlan) {
+	switch {
+	case p.Remote == nil:
+		s.Create++
+	case len(p.Changes) > 0:
+		s.Update++
+	default:
+		s.Unchanged++
+	}
+	if p.MetaStale {
+		s.Write++
+	}
+	for _, sg := range p.Subgroups {
+		s.add(sg)
+	}
+	for _, pp := range p.Projects {
+		switch {
+		case pp.Remote == nil:
+			s.Create++
+		case len(pp.Changes) > 0:
+			s.Update++
+		default:
+			s.Unchanged++
+		}
+	}
+}
+
+// Print writes the plan of p and its subgroups, one line per change.
+func (p *applyGroupPlan) Print(w io.Writer) {
+	kind := "subgroup"
+	if p.Root {
+		kind = "group"
+		fmt.Fprintf(w, "\nPlan for group %s (%s)\n", p.Spec.Name, p.Dir)
+	}
+	switch {
+	case p.Remote == nil:
+		fmt.Fprintf(w, "  %s%-8s %s %s (path %s, %s)%s\n", Cyan, "[CREATE]", kind, p.Label, p.Spec.slug(), p.Visibility, Reset)
+	case len(p.Changes) > 0:
+		fmt.Fprintf(w, "  %s%-8s %s %s: %s%s\n", Yellow, "[UPDATE]", kind, p.Label, strings.Join(p.Changes, ", "), Reset)
+	}
+	if p.RenameFrom != "" {
+		fmt.Fprintf(w, "  %s%-8s %s -> %s (rename folder)%s\n", Yellow, "[REN]", filepath.Base(p.RenameFrom), filepath.Base(p.Dir), Reset)
+	}
+	for _, pp := range p.Projects {
+		switch {
+		case pp.Remote == nil:
+			fmt.Fprintf(w, "  %s%-8s project %s (path %s, %s)%s\n", Cyan, "[CREATE]", pp.Label, pp.Spec.slug(), projectVisibility(pp, p), Reset)
+		case len(pp.Changes) > 0:
+			fmt.Fprintf(w, "  %s%-8s project %s: %s%s\n", Yellow, "[UPDATE]", pp.Label, strings.Join(pp.Changes, ", "), Reset)
+		}
+		if pp.RenameFrom != "" {
+			fmt.Fprintf(w, "  %s%-8s %s -> %s (rename folder)%s\n", Yellow, "[REN]", filepath.Base(pp.RenameFrom), pp.Folder, Reset)
+		}
+	}
+	for _, sg := range p.ExtraSubgroups {
+		fmt.Fprintf(w, "  %s%-8s subgroup %s/%s (not in manifest, left untouched)%s\n", Gray, "[EXTRA]", p.Label, sg.Name, Reset)
+	}
+	for _, prj := range p.ExtraProjects {
+		fmt.Fprintf(w, "  %s%-8s project %s/%s (not in manifest, left untouched)%s\n", Gray, "[EXTRA]", p.Label, prj.Name, Reset)
+	}
+	if p.MetaStale {
+		file := "subgroup.json"
+		if p.Root {
+			file = "group.json"
+		}
+		fmt.Fprintf(w, "  %s%-8s %s%s\n", Gray, "[WRITE]", filepath.Join(p.Label, ".ash", file), Reset)
+	}
+	for _, sg := range p.Subgroups {
+		sg.Print(w)
+	}
+}
+
+// projectVisibility is the visibility a new project of group p gets.
+func projectVisibility(pp *applyProjectPlan, p *applyGroupPlan) string {
+	if pp.Spec.Visibility != "" {
+		return pp.Spec.Visibility
+	}
+	return p.Visibility
+}
+
+// --- APPLY ---
+
+// applyGroup creates or updates the group of p (under parentID for
+// subgroups), then its subgroups and projects, then writes its metadata.
+// Every change is recorded in results; a group that cannot be created
+// skips everything below it.
+func applyGroup(ctx context.Context, api gitlab.API, p *applyGroupPlan, parentID int64, results *[]TaskResult) {
+	if ctx.Err() != nil {
+		skipApplyTree(p, results, "CANCELLED", "Interrupted before completion")
+		return
+	}
+
+	switch {
+	case p.Remote == nil:
+		g, err := api.CreateGroup(ctx, gitlab.CreateGroupOptions{
+			Name:        p.Spec.Name,
+			Path:        p.Spec.slug(),
+			ParentID:    parentID,
+			Visibility:  p.Visibility,
+			Description: p.Spec.Description,
+		})
+		if err != nil {
+			status := "ERR"
+			if ctx.Err() != nil {
+				status = "CANCELLED"
+			}
+			*results = append(*results, TaskResult{Name: p.Label, Status: status, Message: fmt.Sprintf("Create failed: %v", err)})
+			for _, sg := range p.Subgroups {
+				skipApplyTree(sg, results, "SKIP", "Parent group not created")
+			}
+			for _, pp := range p.Projects {
+				*results = append(*results, TaskResult{Name: pp.Label, Status: "SKIP", Message: "Parent group not created"})
+			}
+			return
+		}
+		p.Remote = g
+		*results = append(*results, TaskResult{Name: p.Label, Status: "NEW", Message: "Created"})
+	case len(p.Changes) > 0:
+		if g, err := api.UpdateGroup(ctx, p.Remote.ID, p.Update); err != nil {
+			*results = append(*results, TaskResult{Name: p.Label, Status: "ERR", Message: fmt.Sprintf("Update failed: %v", err)})
+		} else {
+			p.Remote.Name = g.Name
+			*results = append(*results, TaskResult{Name: p.Label, Status: "OK", Message: "Updated " + strings.Join(p.Changes, ", ")})
+		}
+	}
+
+	// Local folder
+	if p.RenameFrom != "" {
+		if err := os.Rename(p.RenameFrom, p.Dir); err != nil {
+			*results = append(*results, TaskResult{Name: p.Label, Status: "ERR", Message: fmt.Sprintf("Rename local folder failed: %v", err)})
+		}
+	}
+	if err := os.MkdirAll(p.Dir, 0o755); err != nil {
+		*results = append(*results, TaskResult{Name: p.Label, Status: "ERR", Message: fmt.Sprintf("Create local folder failed: %v", err)})
+		return
+	}
+
+	for _, sg := range p.Subgroups {
+		applyGroup(ctx, api, sg, p.Remote.ID, results)
+	}
+	for _, pp := range p.Projects {
+		applyProject(ctx, api, pp, p, results)
+	}
+
+	// Metadata lists what exists on GitLab now; failed creations are left out.
+	if !p.metaStale(p.Dir) {
+		return
+	}
+	meta, _ := p.meta()
+	if err := writeApplyMeta(p.Dir, meta); err != nil {
+		*results = append(*results, TaskResult{Name: p.Label, Status: "ERR", Message: fmt.Sprintf("Write metadata failed: %v", err)})
+	}
+}
+
+// applyProject creates or updates one project of the group of p.
+func applyProject(ctx context.Context, api gitlab.API, pp *applyProjectPlan, p *applyGroupPlan, results *[]TaskResult) {
+	if ctx.Err() != nil {
+		*results = append(*results, cancelledResult(pp.Label))
+		return
+	}
+	switch {
+	case pp.Remote == nil:
+		apiCtx, retries := gitlab.WithRetryStats(ctx)
+		prj, err := api.CreateProject(apiCtx, gitlab.CreateProjectOptions{
+			Name:        pp.Spec.Name,
+			Path:        pp.Spec.slug(),
+			NamespaceID: p.Remote.ID,
+			Visibility:  projectVisibility(pp, p),
+			Description: pp.Spec.Description,
+		})
+		switch {
+		case err != nil && ctx.Err() != nil:
+			*results = append(*results, cancelledResult(pp.Label))
+		case err != nil:
+			*results = append(*results, TaskResult{Name: pp.Label, Status: "ERR", Message: withRetries(fmt.Sprintf("Create failed: %v", err), retries)})
+		default:
+			pp.Remote = prj
+			*results = append(*results, TaskResult{Name: pp.Label, Status: "NEW", Message: withRetries("Created", retries)})
+		}
+	case len(pp.Changes) > 0:
+		if _, err := api.UpdateProject(ctx, pp.Remote.ID, pp.Update); err != nil {
+			*results = append(*results, TaskResult{Name: pp.Label, Status: "ERR", Message: fmt.Sprintf("Update failed: %v", err)})
+			return
+		}
+		*results = append(*results, TaskResult{Name: pp.Label, Status: "OK", Message: "Updated " + strings.Join(pp.Changes, ", ")})
+	}
+	if pp.RenameFrom != "" {
+		if err := os.Rename(pp.RenameFrom, filepath.Join(p.Dir, pp.Folder)); err != nil {
+			*results = append(*results, TaskResult{Name: pp.Label, Status: "ERR", Message: fmt.Sprintf("Rename local folder failed: %v", err)})
+		}
+	}
+}
+
+// skipApplyTree records every change below p as not done.
+func skipApplyTree(p *applyGroupPlan, results *[]TaskResult, status, msg string) {
+	if p.Remote == nil || len(p.Changes) > 0 {
+		*results = append(*results, TaskResult{Name: p.Label, Status: status, Message: msg})
+	}
+	for _, sg := range p.Subgroups {
+		skipApplyTree(sg, results, status, msg)
+	}
+	for _, pp := range p.Projects {
+		if pp.Remote == nil || len(pp.Changes) > 0 {
+			*results = append(*results, TaskResult{Name: pp.Label, Status: status, Message: msg})
+		}
+	}
+}
+
+// writeApplyMeta replaces the level metadata in dir with meta, keeping
+// only the template of the old file (the rest is rebuilt from GitLab).
+func writeApplyMeta(dir string, meta levelMeta) error {
+	if _, root, ok := levelMetaFile(dir); ok && root == meta.Root {
+		return updateLevelMeta(dir, func(m *levelMeta) error {
+			meta.Template = m.Template
+			*m = meta
+			return nil
+		})
+	}
+	return writeLevelMeta(dir, meta)
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// isolateConfig keeps the test away from the user's ash config.
+func isolateConfig(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+}
+
+func TestApplyDiffDefaultBranch(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string // default_branch, in the listing too
+		empty  bool   // empty_repo, only in GET /projects/:id
+		want   string // planned default branch update, "" for none
+	}{
+		{"empty repository", "main", true, ""},
+		{"empty repository, other instance default", "master", true, ""},
+		{"no default branch", "", false, ""},
+		{"other default branch", "master", false, "main"},
+		{"same default branch", "main", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfig(t)
+			fetched := false
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/v4/groups/10/subgroups", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[]`))
+			})
+			mux.HandleFunc("GET /api/v4/groups/10/projects", func(w http.ResponseWriter, r *http.Request) {
+				// The simple view, without empty_repo
+				fmt.Fprintf(w, `[{"id": 12, "name": "Lab1", "path": "lab1", "default_branch": %q}]`, tt.branch)
+			})
+			mux.HandleFunc("GET /api/v4/projects/12", func(w http.ResponseWriter, r *http.Request) {
+				fetched = true
+				fmt.Fprintf(w, `{"id": 12, "name": "Lab1", "path": "lab1", "visibility": "public", "default_branch": %q, "empty_repo": %v}`, tt.branch, tt.empty)
+			})
+			standInGitLab(t, mux)
+			api, err := newGitLabClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spec := manifestGroup{Name: "Course", Projects: []manifestProject{{Name: "Lab1", DefaultBranch: "main"}}}
+			remote := &glGroup{ID: 10, Name: "Course", Path: "course", Visibility: "public"}
+			p, err := planApplyGroup(t.Context(), api, spec, remote, "Course", t.TempDir(), "", "public", true)
+			if err != nil {
+				t.Fatalf("planApplyGroup: %v", err)
+			}
+			pp := p.Projects[0]
+			if !fetched {
+				t.Error("the full project was not fetched")
+			}
+			if pp.Update.DefaultBranch != tt.want {
+				t.Errorf("default branch update = %q, want %q", pp.Update.DefaultBranch, tt.want)
+			}
+			if (tt.want == "") != (len(pp.Changes) == 0) {
+				t.Errorf("changes = %q", pp.Changes)
+			}
+		})
+	}
+}
+
+func TestApplyRenamesParentAndChild(t *testing.T) {
+	isolateConfig(t)
+	root := filepath.Join(t.TempDir(), "Course")
+	session := filepath.Join(root, "Session 1")
+	levels := map[string]levelMeta{
+		root: {Root: true, Group: groupIdent{ID: 10, Path: "course", Name: "Course"},
+			Subgroups: []subgroupIdent{{ID: 11, Path: "session-1", Name: "Session 1", Dir: "Session 1"}}},
+		session: {Group: groupIdent{ID: 11, Path: "session-1", Name: "Session 1"},
+			Subgroups: []subgroupIdent{{ID: 13, Path: "week-1", Name: "Week 1", Dir: "Week 1"}},
+			Projects: []projectIdent{
+				{ID: 12, Path: "lab-1", Name: "Lab 1", Dir: "Lab 1"},
+				{ID: 14, Path: "lab-2", Name: "Lab 2", Dir: "my lab"}, // custom folder
+			}},
+		filepath.Join(session, "Week 1"): {Group: groupIdent{ID: 13, Path: "week-1", Name: "Week 1"}},
+	}
+	for dir, meta := range levels {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeLevelMeta(dir, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"Lab 1", "my lab"} {
+		if err := os.MkdirAll(filepath.Join(session, f), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(session, f, "Main.java"), "class Main {}\n")
+	}
+
+	mux := http.NewServeMux()
+	listing := map[string]string{
+		"groups/10/subgroups": `[{"id": 11, "name": "Session 1", "path": "session-1"}]`,
+		"groups/11/subgroups": `[{"id": 13, "name": "Week 1", "path": "week-1"}]`,
+		"groups/11/projects":  `[{"id": 12, "name": "Lab 1", "path": "lab-1"}, {"id": 14, "name": "Lab 2", "path": "lab-2"}]`,
+	}
+	mux.HandleFunc("GET /api/v4/groups/{id}/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := listing["groups/"+r.PathValue("id")+"/"+r.PathValue("kind")]; ok {
+			w.Write([]byte(body))
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("PUT /api/v4/{kind}/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Name string }
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprintf(w, `{"id": %s, "name": %q}`, r.PathValue("id"), body.Name)
+	})
+	standInGitLab(t, mux)
+	api, err := newGitLabClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := manifestGroup{Name: "Course", Subgroups: []manifestGroup{{
+		Name: "Buoi 1", Path: "session-1",
+		Subgroups: []manifestGroup{{Name: "Tuan 1", Path: "week-1"}},
+		Projects:  []manifestProject{{Name: "Bai 1", Path: "lab-1"}, {Name: "Bai 2", Path: "lab-2"}},
+	}}}
+	remote := &glGroup{ID: 10, Name: "Course", Path: "course", Visibility: "public"}
+	p, err := planApplyGroup(t.Context(), api, spec, remote, "Course", root, "", "public", true)
+	if err != nil {
+		t.Fatalf("planApplyGroup: %v", err)
+	}
+	sp := p.Subgroups[0]
+	if sp.RenameFrom != session {
+		t.Errorf("Session 1 RenameFrom = %q, want %q", sp.RenameFrom, session)
+	}
+	if want := filepath.Join(root, "Buoi 1", "Week 1"); sp.Subgroups[0].RenameFrom != want {
+		t.Errorf("Week 1 RenameFrom = %q, want %q", sp.Subgroups[0].RenameFrom, want)
+	}
+	if pp := sp.Projects[0]; pp.Folder != "Bai 1" || pp.RenameFrom != filepath.Join(root, "Buoi 1", "Lab 1") {
+		t.Errorf("Lab 1 folder %q from %q, want Bai 1 from Buoi 1/Lab 1", pp.Folder, pp.RenameFrom)
+	}
+	if pp := sp.Projects[1]; pp.Folder != "my lab" || pp.RenameFrom != "" {
+		t.Errorf("Lab 2 folder %q from %q, want the custom folder kept", pp.Folder, pp.RenameFrom)
+	}
+
+	var results []TaskResult
+	applyGroup(t.Context(), api, p, 0, &results)
+	for _, r := range results {
+		if r.Status == "ERR" {
+			t.Errorf("%s: %s", r.Name, r.Message)
+		}
+	}
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 2 { // .ash and Buoi 1
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("Course holds %q, want only .ash and Buoi 1", names)
+	}
+	for _, f := range []string{"Bai 1/Main.java", "my lab/Main.java", "Tuan 1/.ash/subgroup.json"} {
+		if !fileExists(filepath.Join(root, "Buoi 1", f)) {
+			t.Errorf("Buoi 1/%s missing", f)
+		}
+	}
+	for _, f := range []string{"Lab 1", "Bai 2", "Week 1"} {
+		if fileExists(filepath.Join(root, "Buoi 1", f)) {
+			t.Errorf("Buoi 1/%s left behind", f)
+		}
+	}
+}
@@ -3,10 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"os"
-	"os/exec"
 
 	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 var (
@@ -40,56 +39,43 @@ Examples:
 			return fmt.Errorf("invalid git protocol: %s ( allow ssh or https)", GitProto)
 		}
 
-		login := exec.Command("glab", "auth", "login",
-			"--hostname", Host,
-			"--token", Token,
-			"--api-host", APIHost,
-			"--api-protocol", APIProto,
-			"--git-protocol", GitProto,
-		)
-		login.Stdout = os.Stdout
-		login.Stderr = os.Stderr
-
-		if err := login.Run(); err != nil {
-			return fmt.Errorf("%s glab login failed: %w", icErr, err)
+		// -- Verify the token against the API --
+		cfg, cfgPath, loadErr := loadConfig()
+		if loadErr != nil {
+			// A broken config is replaced by a fresh one below.
+			fmt.Printf("%s[WARN] Ignoring unreadable config %s: %v%s\n", Yellow, cfgPath, loadErr, Reset)
+			cfg = AshConfig{}
 		}
-
-		// --- Verify authentication status ---
-		status := exec.Command("glab", "auth", "status", "--hostname", Host)
-		status.Stdout = os.Stdout
-		status.Stderr = os.Stderr
-
-		// --- Set default host to git.rikkei.edu.vn ---
-		defaultHost := exec.Command("glab", "config", "set", "-g", "host", Host)
-		defaultHost.Stdout = os.Stdout
-		defaultHost.Stderr = os.Stderr
-
-		if err := status.Run(); err != nil {
-			return fmt.Errorf("auth status check failed: %w", err)
+		setLogin := func(c *AshConfig) error {
+			c.Host = Host
+			c.APIHost = APIHost
+			c.APIProtocol = APIProto
+			c.GitProtocol = GitProto
+			c.Token = Token
+			return nil
 		}
+		setLogin(&cfg)
 
-		if err := defaultHost.Run(); err != nil {
-			return fmt.Errorf("failed to set default host: %w", err)
+		client, err := gitlab.NewClient(gitlabBaseURL(cfg), Token)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("%s Default GitLab host set to %s\n", icOk, Host)
-
-		// --- Save Git Protocol Preference ---
-		cfg, cfgPath, err := loadConfig()
+		user, err := client.CurrentUser(cmd.Context())
 		if err != nil {
-			// If config doesn't exist, we can't save preference easily or should create it.
-			// loadConfig returns empty config if not found, but we might want to ensure dir exists.
-			// Usually loadConfig handles the read, but creation might be needed.
-			// saveConfig handles mkdir.
+			return fmt.Errorf("%s login failed: %w", icErr, err)
 		}
+		fmt.Printf("%s Logged in to %s as %s\n", icOk, Host, user.Username)
 
-		// If loadConfig failed, we might have an empty cfg, which is fine to start with.
-		// We just want to update the protocol.
-		cfg.GitProtocol = GitProto
-		if err := saveConfig(cfgPath, cfg); err != nil {
-			fmt.Printf("%s[WARN] Failed to save git protocol preference: %v%s\n", Yellow, err, Reset)
+		// --- Save credentials and Git Protocol Preference ---
+		if loadErr != nil {
+			err = saveConfig(cfgPath, cfg)
 		} else {
-			fmt.Printf("%s Saved git protocol preference: %s\n", icOk, GitProto)
+			_, err = updateConfig(setLogin)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
 		}
+		fmt.Printf("%s Saved login and git protocol preference (%s) to %s\n", icOk, GitProto, cfgPath)
 
 		return nil
 	},
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// --- CANCELLATION ---
+// Execute cancels the root context on Ctrl-C / SIGTERM. Commands take it from
+// cmd.Context() and pass it to every API call and git process. Work that had
+// not finished is reported as CANCELLED and left out of the metadata.
+
+// gitWaitDelay is how long an interrupted git process gets to clean up
+// (lock files, partial clones) before it is killed.
+const gitWaitDelay = 5 * time.Second
+
+// gitCmd builds a git command bound to ctx. On cancellation git receives an
+// interrupt rather than a kill, so it can tidy up the way it does on Ctrl-C.
+func gitCmd(ctx context.Context, args ...string) *exec.Cmd {
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Cancel = func() error {
+		if runtime.GOOS == "windows" {
+			return c.Process.Kill()
+		}
+		return c.Process.Signal(os.Interrupt)
+	}
+	c.WaitDelay = gitWaitDelay
+	return c
+}
+
+// cancelledResult is the TaskResult for a task cut short by cancellation.
+func cancelledResult(name string) TaskResult {
+	return TaskResult{Name: name, Status: "CANCELLED", Message: "Interrupted before completion"}
+}
+
+// quietOnCancel wraps every RunE below c so an interrupted run does not make
+// cobra print "Error: context canceled" and the usage text; Execute reports
+// the interruption instead.
+func quietOnCancel(c *cobra.Command) {
+	if run := c.RunE; run != nil {
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			err := run(cmd, args)
+			if err != nil && cmd.Context().Err() != nil {
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+			}
+			return err
+		}
+	}
+	for _, sub := range c.Commands() {
+		quietOnCancel(sub)
+	}
+}
@@ -10,7 +10,7 @@ import (
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Check system requirements and status",
-	Long:  `Check if git, glab are installed and verify authentication status.`,
+	Long:  `Check if git is installed and verify GitLab authentication status.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Running system health check...")
 		fmt.Println("------------------------------")
@@ -25,22 +25,16 @@ var doctorCmd = &cobra.Command{
 			hasErrors = true
 		}
 
-		// 2. Check Glab CLI
-		if path, err := exec.LookPath("glab"); err == nil {
-			fmt.Printf("[PASS] Glab CLI found at: %s\n", path)
-
-			// 3. Check Authentication (Only if glab is installed)
-			// Check if logged in to any host
-			out, err := exec.Command("glab", "auth", "status").CombinedOutput()
-			if err == nil {
-				fmt.Println("[PASS] Glab authentication: Logged in.")
-			} else {
-				fmt.Printf("[WARN] Glab authentication issue:\n%s\n", string(out))
-				fmt.Println("       Run 'ash auth login' to fix.")
-			}
-		} else {
-			fmt.Println("[FAIL] Glab CLI is NOT installed (Required).")
+		// 2. Check GitLab login (token saved by 'ash auth login')
+		api, err := newGitLabClient()
+		if err != nil {
+			fmt.Printf("[FAIL] GitLab login: %v\n", err)
 			hasErrors = true
+		} else if user, err := api.CurrentUser(cmd.Context()); err != nil {
+			fmt.Printf("[WARN] GitLab authentication issue: %v\n", err)
+			fmt.Println("       Run 'ash auth login' to fix.")
+		} else {
+			fmt.Printf("[PASS] GitLab authentication: Logged in as %s.\n", user.Username)
 		}
 
 		fmt.Println("------------------------------")
@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	exportFile  string
+	exportLocal bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [group...]",
+	Short: "Write a group hierarchy to a manifest (the reverse of apply)",
+	Long: `Walk one or more groups on GitLab and write a manifest with their subgroups
+and projects, visibility, descriptions and default branches. Edit it and feed
+it to 'ash apply' to recreate the structure, e.g. for a new class.
+
+Groups are given by name (as in 'ash group list') or by full path. Without
+arguments the group of the current folder is exported.
+
+To keep the manifest short, settings that 'ash apply' would derive anyway are
+left out: a path derived from the name, and a visibility equal to the
+parent's. Projects named Prefix1..PrefixN with the same settings are written
+as one prefix/count batch.
+
+With --local, the hierarchy is read from the .ash metadata of the current
+group instead of GitLab (names and paths only; no API calls).
+
+The manifest is YAML, or JSON with -o json or a --file ending in .json.`,
+	Example: `  ash export > course.yaml
+  ash export "CNTT2 - Spring 2025" -f spring.yaml
+  ash export --local -o json`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		var m manifest
+		if exportLocal {
+			if len(args) > 0 {
+				return fmt.Errorf("--local exports the group of the current folder; do not pass group names")
+			}
+			ws, err := currentWorkspace()
+			if err != nil {
+				return err
+			}
+			root, err := ws.groupRoot()
+			if err != nil {
+				return err
+			}
+			g, err := exportLocalLevel(root)
+			if err != nil {
+				return err
+			}
+			m.Groups = append(m.Groups, g)
+		} else {
+			api, err := newGitLabClient()
+			if err != nil {
+				return err
+			}
+			groups, err := resolveExportGroups(ctx, api, args)
+			if err != nil {
+				return err
+			}
+			err = RunSpinner("Reading group hierarchy from GitLab", func() error {
+				for _, g := range groups {
+					mg, err := exportRemoteGroup(ctx, api, g, "")
+					if err != nil {
+						return err
+					}
+					m.Groups = append(m.Groups, mg)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return writeManifest(&m, exportFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportFile, "file", "f", "", "Write the manifest to this file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportLocal, "local", false, "Read the hierarchy from local .ash metadata instead of GitLab")
+}
+
+// resolveExportGroups finds the groups to export: by name in the config, by
+// full path on GitLab, or the group of the current folder.
+func resolveExportGroups(ctx context.Context, api gitlab.API, args []string) ([]*glGroup, error) {
+	if len(args) == 0 {
+		ws, err := currentWorkspace()
+		if err != nil {
+			return nil, err
+		}
+		root, err := ws.groupRoot()
+		if err != nil {
+			return nil, fmt.Errorf("%w; pass a group name to export", err)
+		}
+		var meta rootGroupMeta
+		if err := readGroupMeta(filepath.Join(root, ".ash", "group.json"), &meta); err != nil {
+			return nil, err
+		}
+		g, err := api.GetGroup(ctx, meta.Group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("look up group %s: %w", meta.Group.Name, err)
+		}
+		return []*glGroup{g}, nil
+	}
+
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var groups []*glGroup
+	for _, name := range args {
+		var g *glGroup
+		if cg, ok := findGroupByName(cfg, name); ok {
+			g, err = api.GetGroup(ctx, cg.ID)
+		} else {
+			g, err = api.GetGroupByPath(ctx, strings.Trim(name, "/"))
+		}
+		if gitlab.IsNotFound(err) {
+			return nil, fmt.Errorf("group %q not found by name or path; run 'ash group get' to refresh the group list", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("look up group %s: %w", name, err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// --- EXPORT FROM GITLAB ---
+
+// exportRemoteGroup builds the manifest entry of g and everything below it.
+// parentVis is the visibility apply would inherit ("" for a top-level group).
+func exportRemoteGroup(ctx context.Context, api gitlab.API, g *glGroup, parentVis string) (manifestGroup, error) {
+	mg := manifestGroup{Name: g.Name, Description: g.Description}
+	if !slugMatches(g.Path, g.Name) {
+		mg.Path = g.Path
+	}
+	if g.Visibility != parentVis {
+		mg.Visibility = g.Visibility
+	}
+
+	sgs, err := api.ListSubgroups(ctx, g.ID)
+	if err != nil {
+		return mg, fmt.Errorf("list subgroups of %s: %w", g.Name, err)
+	}
+	sort.Slice(sgs, func(i, j int) bool { return naturalLess(sgs[i].Name, sgs[j].Name) })
+	for i := range sgs {
+		if sgs[i].MarkedForDeletionOn != "" {
+			continue
+		}
+		child, err := exportRemoteGroup(ctx, api, &sgs[i], g.Visibility)
+		if err != nil {
+			return mg, err
+		}
+		mg.Subgroups = append(mg.Subgroups, child)
+	}
+
+	prjs, err := api.ListGroupProjects(ctx, g.ID)
+	if err != nil {
+		return mg, fmt.Errorf("list projects of %s: %w", g.Name, err)
+	}
+	if err := fillProjectVisibility(ctx, api, prjs); err != nil {
+		return mg, fmt.Errorf("inspect projects of %s: %w", g.Name, err)
+	}
+	sort.Slice(prjs, func(i, j int) bool { return naturalLess(prjs[i].Name, prjs[j].Name) })
+	var mps []manifestProject
+	for _, p := range prjs {
+		mp := manifestProject{Name: p.Name, Description: p.Description, DefaultBranch: p.DefaultBranch}
+		if !slugMatches(p.Path, p.Name) {
+			mp.Path = p.Path
+		}
+		if p.Visibility != g.Visibility {
+			mp.Visibility = p.Visibility
+		}
+		mps = append(mps, mp)
+	}
+	mg.Projects = compactProjects(mps)
+	return mg, nil
+}
+
+// fillProjectVisibility looks up the visibility missing from the simple
+// project listing, with up to --jobs requests in flight.
+func fillProjectVisibility(ctx context.Context, api gitlab.API, prjs []glProject) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	sem := make(chan struct{}, jobCount())
+	for i := range prjs {
+		if prjs[i].Visibility != "" {
+			continue
+		}
+		wg.Add(1)
+		go func(p *glProject) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			full, err := api.GetProject(ctx, p.ID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			p.Visibility = full.Visibility
+		}(&prjs[i])
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// --- EXPORT FROM METADATA ---
+
+// exportLocalLevel builds the manifest entry of the level in dir from its
+// .ash metadata, recursing into subgroup folders that exist locally.
+func exportLocalLevel(dir string) (manifestGroup, error) {
+	meta, err := readLevelMeta(dir)
+	if err != nil {
+		return manifestGroup{}, err
+	}
+	mg := manifestGroup{Name: meta.Group.Name}
+	if !slugMatches(meta.Group.Path, meta.Group.Name) {
+		mg.Path = meta.Group.Path
+	}
+
+	sgs := append([]subgroupIdent(nil), meta.Subgroups...)
+	sort.Slice(sgs, func(i, j int) bool { return naturalLess(sgs[i].Name, sgs[j].Name) })
+	for _, sg := range sgs {
+		sgDir := filepath.Join(dir, sg.Dir)
+		if _, _, ok := levelMetaFile(sgDir); ok {
+			child, err := exportLocalLevel(sgDir)
+			if err != nil {
+				return mg, err
+			}
+			mg.Subgroups = append(mg.Subgroups, child)
+			continue
+		}
+		// Not scaffolded locally: only what the parent knows.
+		child := manifestGroup{Name: sg.Name}
+		if !slugMatches(sg.Path, sg.Name) {
+			child.Path = sg.Path
+		}
+		mg.Subgroups = append(mg.Subgroups, child)
+	}
+
+	prjs := append([]projectIdent(nil), meta.Projects...)
+	sort.Slice(prjs, func(i, j int) bool { return naturalLess(prjs[i].Name, prjs[j].Name) })
+	var mps []manifestProject
+	for _, p := range prjs {
+		mp := manifestProject{Name: p.Name}
+		if !slugMatches(p.Path, p.Name) {
+			mp.Path = p.Path
+		}
+		mps = append(mps, mp)
+	}
+	mg.Projects = compactProjects(mps)
+	return mg, nil
+}
+
+// --- MANIFEST OUTPUT ---
+
+var numberedName = regexp.MustCompile(`^(.*?)(\d+)$`)
+
+// compactProjects folds runs of projects named Prefix1..PrefixN (in order,
+// with derived paths and identical settings) into one prefix/count batch,
+// which expand() turns back into the same projects.
+func compactProjects(prjs []manifestProject) []manifestProject {
+	var out []manifestProject
+	for i := 0; i < len(prjs); {
+		prefix, n := batchPosition(prjs[i])
+		if n != 1 {
+			out = append(out, prjs[i])
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(prjs) {
+			p, k := batchPosition(prjs[j])
+			if p != prefix || k != j-i+1 || !sameProjectSettings(prjs[i], prjs[j]) {
+				break
+			}
+			j++
+		}
+		if j-i < 2 {
+			out = append(out, prjs[i])
+		} else {
+			batch := prjs[i]
+			batch.Name, batch.Prefix, batch.Count = "", prefix, j-i
+			out = append(out, batch)
+		}
+		i = j
+	}
+	return out
+}
+
+// batchPosition splits "Lab3" into ("Lab", 3); n is 0 when p cannot be part
+// of a batch.
+func batchPosition(p manifestProject) (prefix string, n int) {
+	if p.Path != "" {
+		return "", 0
+	}
+	m := numberedName.FindStringSubmatch(p.Name)
+	if m == nil || m[1] == "" || strings.HasPrefix(m[2], "0") {
+		return "", 0
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0
+	}
+	return m[1], n
+}
+
+func sameProjectSettings(a, b manifestProject) bool {
+	return a.Description == b.Description && a.Visibility == b.Visibility && a.DefaultBranch == b.DefaultBranch
+}
+
+// naturalLess orders names with numbers by value: Lab2 before Lab10.
+func naturalLess(a, b string) bool {
+	ma, mb := numberedName.FindStringSubmatch(a), numberedName.FindStringSubmatch(b)
+	if ma != nil && mb != nil && ma[1] == mb[1] {
+		na, _ := strconv.Atoi(ma[2])
+		nb, _ := strconv.Atoi(mb[2])
+		if na != nb {
+			return na < nb
+		}
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+// writeManifest writes m to path (stdout when empty) as JSON with -o json or
+// a .json file, YAML otherwise.
+func writeManifest(m *manifest, path string) error {
+	var w io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	asJSON := outputFormat == outputJSON ||
+		(outputFormat != outputYAML && strings.EqualFold(filepath.Ext(path), ".json"))
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	} else {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+
+	if path != "" {
+		fmt.Fprintf(os.Stderr, "%s[OK] Manifest written to %s%s\n", Green, path, Reset)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import "testing"
+
+func TestExportLocalLevelRootPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"java-course", ""},
+		{"Java-Course", ""}, // GitLab keeps the case a group was created with
+		{"jc", "jc"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		meta := levelMeta{Root: true, Group: groupIdent{ID: 1, Name: "Java Course", Path: tt.path}}
+		if err := writeLevelMeta(dir, meta); err != nil {
+			t.Fatal(err)
+		}
+		mg, err := exportLocalLevel(dir)
+		if err != nil {
+			t.Fatalf("exportLocalLevel: %v", err)
+		}
+		if mg.Path != tt.want {
+			t.Errorf("path %q exported as %q, want %q", tt.path, mg.Path, tt.want)
+		}
+	}
+}
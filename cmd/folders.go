@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// --- LOCAL FOLDERS ---
+// Every subgroup and project entry in the metadata records its local folder
+// (Dir), so the folder no longer has to equal the GitLab name. New folders
+// are named by a strategy:
+//   name  the GitLab display name, made safe for the file system (default)
+//   path  the GitLab path (slug)
+// A folder that differs from what the strategy gives (subgroup create --dir,
+// or a folder renamed by hand and recorded by 'ash meta repair') is custom:
+// it is kept as is when the entity is renamed on GitLab.
+
+const (
+	folderByName = "name"
+	folderByPath = "path"
+)
+
+var flagFolderNaming string
+
+// folderNaming returns the strategy for new folders: --folder-naming, else
+// the folder_naming config key, else "name".
+func folderNaming() string {
+	if rootCmd.PersistentFlags().Changed("folder-naming") {
+		return flagFolderNaming
+	}
+	if cfg, _, err := loadConfig(); err == nil && cfg.FolderNaming != "" {
+		return cfg.FolderNaming
+	}
+	return folderByName
+}
+
+func validateFolderNaming() error {
+	s := flagFolderNaming
+	if cfg, _, err := loadConfig(); err == nil && cfg.FolderNaming != "" && s == folderByName {
+		s = cfg.FolderNaming
+	}
+	switch s {
+	case folderByName, folderByPath:
+		return nil
+	default:
+		return fmt.Errorf("invalid folder naming %q (must be %s or %s)", s, folderByName, folderByPath)
+	}
+}
+
+// safeFolderName turns s into a single folder name that is valid on Linux,
+// macOS and Windows: path separators and the characters Windows refuses
+// become '-', and trailing dots and spaces are dropped.
+func safeFolderName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	s = strings.TrimRight(s, ". ")
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+// defaultFolder is the folder the current strategy gives an entity.
+func defaultFolder(name, path string) string {
+	if folderNaming() == folderByPath && path != "" {
+		return safeFolderName(path)
+	}
+	return safeFolderName(name)
+}
+
+// isDefaultFolder reports whether dir was named by a strategy rather than
+// chosen by the user. Both strategies count, so switching strategy does
+// not turn existing folders into custom ones.
+func isDefaultFolder(dir, name, path string) bool {
+	return dir == safeFolderName(name) || dir == name || (path != "" && dir == safeFolderName(path))
+}
+
+// followRename returns the folder of an entity renamed on GitLab from
+// (oldName, oldPath) to (name, path): a default folder follows the new
+// name, a custom one is kept.
+func followRename(dir, oldName, oldPath, name, path string) string {
+	if dir == "" || isDefaultFolder(dir, oldName, oldPath) {
+		return defaultFolder(name, path)
+	}
+	return dir
+}
+
+func newSubgroupIdent(sg glGroup) subgroupIdent {
+	return subgroupIdent{ID: sg.ID, Name: sg.Name, Path: sg.Path, Dir: defaultFolder(sg.Name, sg.Path)}
+}
+
+func newProjectIdent(p glProject) projectIdent {
+	return projectIdent{ID: p.ID, Name: p.Name, Path: p.Path, Dir: defaultFolder(p.Name, p.Path)}
+}
+
+// findProject finds a project by its GitLab name, else by its folder.
+func findProject(prjs []projectIdent, name string) (projectIdent, bool) {
+	for _, p := range prjs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	for _, p := range prjs {
+		if p.Dir == name {
+			return p, true
+		}
+	}
+	return projectIdent{}, false
+}
+
+// findSubgroup finds a subgroup by its GitLab name, else by its folder.
+func findSubgroup(sgs []subgroupIdent, name string) (subgroupIdent, bool) {
+	for _, sg := range sgs {
+		if sg.Name == name {
+			return sg, true
+		}
+	}
+	for _, sg := range sgs {
+		if sg.Dir == name {
+			return sg, true
+		}
+	}
+	return subgroupIdent{}, false
+}
+
+// knownFolders maps the IDs listed in the metadata of the level folder dir
+// to their folders (empty when dir is not a level yet).
+func knownFolders(dir string) map[int64]string {
+	known := make(map[int64]string)
+	meta, err := readLevelMeta(dir)
+	if err != nil {
+		return known
+	}
+	for _, sg := range meta.Subgroups {
+		known[sg.ID] = sg.Dir
+	}
+	for _, p := range meta.Projects {
+		known[p.ID] = p.Dir
+	}
+	return known
+}
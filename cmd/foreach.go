@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	foreachFilters  []string
+	foreachFailFast bool
+)
+
+var foreachCmd = &cobra.Command{
+	Use:   "foreach [flags] -- <command> [args...]",
+	Short: "Run a command in every project repository",
+	Long: `Run a command in the folder of every project of the current group or
+subgroup and of its nested subgroups, up to --jobs at a time. The output of
+each project is captured and printed once it finishes, followed by a summary.
+
+A single argument is run by the shell (sh -c, or cmd /C on Windows), so it
+may use pipes and &&; several arguments are run as is. The command gets
+ASH_PROJECT (name), ASH_PROJECT_PATH, ASH_PROJECT_ID and ASH_PROJECT_DIR in
+its environment.
+
+--filter selects projects by a glob matched against the project name, its
+folder or its folder relative to here (e.g. "Lab*" or "Session 1/*").`,
+	Example: `  ash foreach -- go test ./...
+  ash foreach -- git checkout main
+  ash foreach --filter 'Lab*' -- 'make fmt && git status --short'
+  ash foreach --fail-fast -j 1 -- go vet ./...`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, f := range foreachFilters {
+			if _, err := path.Match(f, ""); err != nil {
+				return fmt.Errorf("invalid --filter %q: %w", f, err)
+			}
+		}
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
+		}
+		root, err := ws.levelRoot()
+		if err != nil {
+			return err
+		}
+		prjs, err := treeProjects(root)
+		if err != nil {
+			return err
+		}
+		var targets []treeProject
+		for _, p := range prjs {
+			if foreachMatches(p) {
+				targets = append(targets, p)
+			}
+		}
+		if len(targets) == 0 {
+			fmt.Fprintf(infoOut(), "%s[INFO] No projects matched.%s\n", Yellow, Reset)
+			if structuredOutput() {
+				PrintResults(nil)
+			}
+			return nil
+		}
+
+		ctx := cmd.Context()
+		runs := make([]foreachRun, len(targets))
+		title := fmt.Sprintf("Running %q in %d project(s)...", strings.Join(args, " "), len(targets))
+		err = RunSpinner(title, func() error {
+			runForeach(ctx, targets, args, runs)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		results := make([]TaskResult, len(runs))
+		failed := 0
+		for i, r := range runs {
+			if !structuredOutput() && len(r.Output) > 0 {
+				fmt.Printf("%s== %s ==%s\n%s", Cyan, targets[i].Rel, Reset, r.Output)
+				if !strings.HasSuffix(string(r.Output), "\n") {
+					fmt.Println()
+				}
+			}
+			results[i] = r.Result
+			if r.Result.Status == "ERR" {
+				failed++
+			}
+		}
+		PrintResults(results)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("command failed in %d of %d project(s)", failed, len(targets))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(foreachCmd)
+	foreachCmd.Flags().SetInterspersed(false) // flags after the command are its own
+	foreachCmd.Flags().StringSliceVar(&foreachFilters, "filter", nil, "Only projects whose name or folder matches this glob (repeatable)")
+	foreachCmd.Flags().BoolVar(&foreachFailFast, "fail-fast", false, "Stop at the first failure: start no more projects and stop the running ones")
+}
+
+// foreachRun is the outcome of the command in one project.
+type foreachRun struct {
+	Result TaskResult
+	Output []byte // stdout and stderr, interleaved
+}
+
+// foreachMatches reports whether p is selected by --filter (all are without one).
+func foreachMatches(p treeProject) bool {
+	if len(foreachFilters) == 0 {
+		return true
+	}
+	rel := filepath.ToSlash(p.Rel)
+	for _, f := range foreachFilters {
+		for _, s := range []string{p.Name, p.projectIdent.Dir, rel} {
+			if ok, _ := path.Match(f, s); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runForeach runs args in every target through gitPool(), filling runs in
+// target order. With --fail-fast the first failure cancels the rest.
+func runForeach(ctx context.Context, targets []treeProject, args []string, runs []foreachRun) {
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+	errFailFast := errors.New("stopped after a failure")
+
+	var wg sync.WaitGroup
+	workers := gitPool()
+	for i, p := range targets {
+		name := p.Rel
+		if !fileExists(p.Dir) {
+			runs[i].Result = TaskResult{Name: name, Status: "SKIP", Message: "Not cloned"}
+			continue
+		}
+		// Slots are taken in order, so projects start in the order listed.
+		if !workers.acquire(ctx) {
+			runs[i].Result = foreachStopped(ctx, name, errFailFast, "Not run")
+			continue
+		}
+		wg.Add(1)
+		go func(i int, p treeProject) {
+			defer wg.Done()
+			defer workers.release()
+
+			out, err := foreachCommand(ctx, p, args).CombinedOutput()
+			runs[i].Output = out
+			switch {
+			case err == nil:
+				runs[i].Result = TaskResult{Name: name, Status: "OK", Message: "Done"}
+			case ctx.Err() != nil:
+				runs[i].Result = foreachStopped(ctx, name, errFailFast, "Interrupted")
+			default:
+				runs[i].Result = TaskResult{Name: name, Status: "ERR", Message: foreachFailure(err, out)}
+				if foreachFailFast {
+					stop(errFailFast)
+				}
+			}
+		}(i, p)
+	}
+	wg.Wait()
+}
+
+// foreachStopped reports a project that did not run to the end, either
+// because of --fail-fast or because the user interrupted ash.
+func foreachStopped(ctx context.Context, name string, errFailFast error, what string) TaskResult {
+	if errors.Is(context.Cause(ctx), errFailFast) {
+		return TaskResult{Name: name, Status: "SKIP", Message: what + " (" + errFailFast.Error() + ")"}
+	}
+	return cancelledResult(name)
+}
+
+// foreachCommand builds the command for project p: through the shell for a
+// single argument, directly otherwise.
+func foreachCommand(ctx context.Context, p treeProject, args []string) *exec.Cmd {
+	var c *exec.Cmd
+	switch {
+	case len(args) > 1:
+		c = exec.CommandContext(ctx, args[0], args[1:]...)
+	case runtime.GOOS == "windows":
+		c = exec.CommandContext(ctx, "cmd", "/C", args[0])
+	default:
+		c = exec.CommandContext(ctx, "sh", "-c", args[0])
+	}
+	c.Dir = p.Dir
+	c.Env = append(os.Environ(),
+		"ASH_PROJECT="+p.Name,
+		"ASH_PROJECT_PATH="+p.Path,
+		"ASH_PROJECT_ID="+strconv.FormatInt(p.ID, 10),
+		"ASH_PROJECT_DIR="+p.Dir,
+	)
+	return c
+}
+
+// foreachFailure describes a failed run: the exit status and the last line
+// of output, which usually says what went wrong.
+func foreachFailure(err error, out []byte) string {
+	msg := err.Error()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		msg = fmt.Sprintf("Exit status %d", ee.ExitCode())
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		if rs := []rune(last); len(rs) > 60 {
+			last = string(rs[:59]) + "…"
+		}
+		msg += ": " + last
+	}
+	return msg
+}
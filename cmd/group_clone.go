@@ -40,14 +40,23 @@ var groupCloneCmd = &cobra.Command{
 		if proto != "ssh" && proto != "https" {
 			proto = "https"
 		}
-		fmt.Printf("Cloning full hierarchy into %s (protocol: %s)\n", groupName, proto)
+		dir := defaultFolder(grp.Name, grp.Path)
+		fmt.Printf("Cloning full hierarchy into %s (protocol: %s)\n", dir, proto)
 
-		if err := scaffoldLocalGroup(groupName, grp); err != nil {
+		if err := scaffoldLocalGroup(dir, grp); err != nil {
 			return err
 		}
-		return RunSpinner(fmt.Sprintf("Cloning hierarchy into %s", groupName), func() error {
-			return cloneGroupHierarchy(groupIdent{ID: grp.ID, Path: grp.Path}, groupName, proto, true)
+		var report *cloneReport
+		err = RunSpinner(fmt.Sprintf("Cloning hierarchy into %s", dir), func() error {
+			var err error
+			report, err = cloneGroupHierarchy(cmd.Context(), groupIdent{ID: grp.ID, Path: grp.Path, Name: grp.Name}, dir, proto, true)
+			return err
 		})
+		report.Print()
+		if err != nil {
+			return err
+		}
+		return report.Err()
 	},
 }
 
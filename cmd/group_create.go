@@ -1,11 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os/exec"
 
 	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 var groupCreateCmd = &cobra.Command{
@@ -20,7 +20,7 @@ var groupCreateCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		groupName := args[0]
 		return RunSpinner(fmt.Sprintf("Creating group %s", groupName), func() error {
-			return createNewGroup(groupName, groupName)
+			return createNewGroup(cmd.Context(), groupName, defaultFolder(groupName, slugify(groupName)))
 		})
 	},
 }
@@ -29,7 +29,7 @@ func init() {
 	groupCmd.AddCommand(groupCreateCmd)
 }
 
-func createNewGroup(name, dir string) error {
+func createNewGroup(ctx context.Context, name, dir string) error {
 	cfg, _, err := loadConfig()
 	if err != nil {
 		return err
@@ -41,33 +41,32 @@ func createNewGroup(name, dir string) error {
 	}
 
 	slug := slugify(name)
-	fmt.Printf("Creating group via glab: name=%q path=%q visibility=public\n", name, slug)
-
-	glabCmd := exec.Command("glab", "api", "-X", "POST", "/groups",
-		"-f", "name="+name,
-		"-f", "path="+slug,
-		"-f", "visibility=public",
-	)
-	out, err := glabCmd.Output()
-	if err != nil {
-		return fmt.Errorf("glab failed: %w", err)
+	if err := checkPath("group", slug); err != nil {
+		return err
 	}
 
-	var created struct {
-		ID   int64  `json:"id"`
-		Name string `json:"name"`
-		Path string `json:"path"`
+	api, err := newGitLabClient()
+	if err != nil {
+		return err
 	}
-	if err := json.Unmarshal(out, &created); err != nil {
-		return fmt.Errorf("failed to parse glab response: %w", err)
+	if g, err := api.GetGroupByPath(ctx, slug); err == nil {
+		return fmt.Errorf("path %q is already used by group %q; choose another name (or run 'ash group get' if it is yours)", slug, g.Name)
+	} else if !gitlab.IsNotFound(err) {
+		return fmt.Errorf("check existing group failed: %w", err)
 	}
-	if created.ID == 0 || created.Path == "" {
-		return fmt.Errorf("unexpected glab response: missing id/path")
+	fmt.Printf("Creating group: name=%q path=%q visibility=public\n", name, slug)
+	created, err := api.CreateGroup(ctx, gitlab.CreateGroupOptions{
+		Name:       name,
+		Path:       slug,
+		Visibility: "public",
+	})
+	if err != nil {
+		return fmt.Errorf("create group failed: %w", err)
 	}
 
 	fmt.Printf("Created group: id=%d path=%q\n", created.ID, created.Path)
 
-	if err := fetchAndSaveGroups(); err != nil {
+	if err := fetchAndSaveGroups(ctx); err != nil {
 		return fmt.Errorf("resync config after create failed: %w", err)
 	}
 
@@ -1,11 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -19,12 +18,15 @@ var groupDeleteCmd = &cobra.Command{
 	Use:   "delete [name]",
 	Short: "Delete a top-level GitLab group",
 	Long: `Delete a top-level GitLab group.
-If run inside a simplified group folder (with .ash/group.json), it attempts to delete the current group.
+If run anywhere inside a group folder (below .ash/group.json), it attempts to delete the current group.
 Otherwise, a group name argument is required.
 
 Safety: By default, it refuses to delete non-empty groups on GitLab.
 Use --force (-f) to force deletion on GitLab.
-Use --local-force (-l) to also delete the local folder.`,
+Use --local-force (-l) to also move the local folder to the trash
+(see 'ash trash'). Repositories in it
+holding uncommitted, unpushed or stashed work are reported first, and the
+deletion is refused unless confirmed or --discard-local-work is given.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 
@@ -35,12 +37,15 @@ Use --local-force (-l) to also delete the local folder.`,
 		if len(args) > 0 {
 			groupName = args[0]
 		} else {
-			// Check if we are in a group root
-			wd, _ := os.Getwd()
-			metaPath := filepath.Join(wd, ".ash", "group.json")
-			if fileExists(metaPath) {
+			// Check if we are anywhere inside a group folder
+			ws, err := currentWorkspace()
+			if err != nil {
+				return err
+			}
+			if ws.GroupRoot != "" {
+				metaPath := filepath.Join(ws.GroupRoot, ".ash", "group.json")
 				var meta rootGroupMeta
-				if err := readJSON(metaPath, &meta); err == nil && meta.Group.Name != "" {
+				if err := readGroupMeta(metaPath, &meta); err == nil && meta.Group.Name != "" {
 					groupName = meta.Group.Name
 					fmt.Printf("Detected current group: %s\n", groupName)
 				}
@@ -51,7 +56,7 @@ Use --local-force (-l) to also delete the local folder.`,
 			return fmt.Errorf("missing group name and not in a group folder")
 		}
 
-		return deleteGroup(groupName)
+		return deleteGroup(cmd.Context(), groupName)
 	},
 }
 
@@ -59,9 +64,10 @@ func init() {
 	groupCmd.AddCommand(groupDeleteCmd)
 	groupDeleteCmd.Flags().BoolVarP(&forceDelete, "force", "f", false, "Force delete on GitLab even if not empty")
 	groupDeleteCmd.Flags().BoolVarP(&localForceDelete, "local-force", "l", false, "Also delete local folder")
+	groupDeleteCmd.Flags().BoolVar(&discardLocalWork, "discard-local-work", false, "With -l, delete the folder even if it holds uncommitted or unpushed work")
 }
 
-func deleteGroup(name string) error {
+func deleteGroup(ctx context.Context, name string) error {
 	cfg, cfgPath, err := loadConfig()
 	if err != nil {
 		return err
@@ -74,7 +80,7 @@ func deleteGroup(name string) error {
 
 	fmt.Printf("Deleting group %q (ID: %d) from GitLab...\n", g.Name, g.ID)
 
-	// API call logic: glab api -X DELETE /groups/:id
+	// API call logic: DELETE /groups/:id
 	// If force is not set, we trust GitLab API to fail if not empty (or we assume standard behavior).
 	// But usually DELETE /groups/:id happens async or requires more permissions.
 	// NOTE: GitLab API doesn't strictly block "non-empty" delete, it schedules for deletion.
@@ -84,18 +90,56 @@ func deleteGroup(name string) error {
 
 	if !forceDelete {
 		// Check emptiness
-		sgs, _ := apiListSubgroups(g.ID)
-		prjs, _ := apiListProjects(g.ID)
+		sgs, err := apiListSubgroups(ctx, g.ID)
+		if err != nil {
+			return fmt.Errorf("check group content failed: %w", err)
+		}
+		prjs, err := apiListProjects(ctx, g.ID)
+		if err != nil {
+			return fmt.Errorf("check group content failed: %w", err)
+		}
 		if len(sgs) > 0 || len(prjs) > 0 {
 			return fmt.Errorf("group is not empty (contains %d subgroups, %d projects). Use -f to force delete", len(sgs), len(prjs))
 		}
 	}
 
+	// Resolve the local folder before anything is deleted so local work can be checked.
+	// Since we don't store local path in config, we guess the default folder, then
+	// 'Name' or 'Path' relative to CWD, unless we are standing inside that group's folder.
+	ws, err := currentWorkspace()
+	if err != nil {
+		return err
+	}
+	wd := ws.Dir
+	target := filepath.Join(wd, defaultFolder(g.Name, g.Path))
+	for _, guess := range []string{g.Name, g.Path} {
+		if !fileExists(target) {
+			target = filepath.Join(wd, guess)
+		}
+	}
+	if ws.GroupRoot != "" {
+		var meta rootGroupMeta
+		if err := readGroupMeta(filepath.Join(ws.GroupRoot, ".ash", "group.json"), &meta); err == nil && meta.Group.ID == g.ID {
+			target = ws.GroupRoot
+		}
+	}
+	if localForceDelete {
+		if rel, err := filepath.Rel(target, wd); err == nil && !strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("cannot delete local folder while inside it. Please cd %s and run again", filepath.Dir(target))
+		}
+		if err := checkLocalWork(ctx, target); err != nil {
+			return err
+		}
+	}
+
 	// API Delete
+	api, err := newGitLabClient()
+	if err != nil {
+		return err
+	}
 	err = RunSpinner(fmt.Sprintf("Deleting group %s (ID: %d)", g.Name, g.ID), func() error {
-		glabCmd := exec.Command("glab", "api", "-X", "DELETE", "/groups/"+strconv.FormatInt(g.ID, 10))
-		if out, err := glabCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to delete group on GitLab: %s (%w)", string(out), err)
+		if err := api.DeleteGroup(ctx, g.ID); err != nil {
+			return fmt.Errorf("failed to delete group on GitLab: %w", err)
 		}
 		return nil
 	})
@@ -104,45 +148,26 @@ func deleteGroup(name string) error {
 	}
 
 	// Remove from config
-	newGroups := []GitLabGroup{}
-	for _, grp := range cfg.Groups {
-		if grp.ID != g.ID {
-			newGroups = append(newGroups, grp)
+	cfgPath, err = updateConfig(func(cfg *AshConfig) error {
+		newGroups := []GitLabGroup{}
+		for _, grp := range cfg.Groups {
+			if grp.ID != g.ID {
+				newGroups = append(newGroups, grp)
+			}
 		}
-	}
-	cfg.Groups = newGroups
-	if err := saveConfig(cfgPath, cfg); err != nil {
+		cfg.Groups = newGroups
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 	fmt.Printf("Updated config: %s\n", cfgPath)
 
 	// Local delete
 	if localForceDelete {
-		// Caution: We need to know WHERE the local folder is.
-		// If the user is running by Name and the folder is elsewhere, we might not know.
-		// BUT if we assume standard structure or check common paths...
-		// Best effort: if we are IN the folder, we can't fully remove it (busy).
-		// If we are outside, we can look for it.
-		// For now, let's assume we delete `slugify(name)` or the matched path in current dir?
-		// Since we don't store local path in config, we can only guess 'Name' or 'Path' relative to CWD?
-		// Or we only support this if context-aware?
-		wd, _ := os.Getwd()
-		target := filepath.Join(wd, g.Name)
-		if !fileExists(target) {
-			target = filepath.Join(wd, g.Path)
-		}
-
 		if fileExists(target) {
-			if err := os.RemoveAll(target); err != nil {
+			if err := removeDir(target, "group", g.ID, g.Name); err != nil {
 				fmt.Printf("%s[WARN] Failed to delete local folder %s: %v%s\n", Yellow, target, err, Reset)
-			} else {
-				fmt.Printf("%s[DEL] Deleted local folder: %s%s\n", Red, target, Reset)
-			}
-		} else {
-			// Handle case: we are inside the folder
-			cwdName := filepath.Base(wd)
-			if cwdName == g.Name || cwdName == g.Path {
-				fmt.Printf("%s[WARN] Cannot delete local folder while inside it. Please cd .. and run again.%s\n", Yellow, Reset)
 			}
 		}
 	}
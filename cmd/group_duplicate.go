@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
+)
+
+var (
+	dupCopyCode bool
+	dupTemplate string
+	dupDryRun   bool
+	dupProto    string
+)
+
+var groupDuplicateCmd = &cobra.Command{
+	Use:   "duplicate [source] [new-name]",
+	Short: "Create a new group with the same subgroups and projects as an existing one",
+	Long: `Roll a group over to a new semester: create a new top-level group with the
+same subgroup tree and projects as the source group (names, visibility,
+descriptions, default branches), and scaffold it locally in a folder named
+after the new group.
+
+The new projects are empty. With --copy-code, the content of each source
+project's default branch is pushed to its copy as a single "starter code"
+commit (the source history is not copied). With --template, every new
+project is seeded from that template instead, as 'ash project create' does.
+
+The source is given by name (as in 'ash group list') or full path. Projects
+are not cloned; run 'ash group sync' in the new folder afterwards.`,
+	Example: `  ash group duplicate "CNTT2 - Spring 2025" "CNTT3 - Fall 2025" --dry-run
+  ash group duplicate "CNTT2 - Spring 2025" "CNTT3 - Fall 2025" --copy-code
+  ash group duplicate "CNTT2 - Spring 2025" "CNTT3 - Fall 2025" --template java-lab`,
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		newName := strings.TrimSpace(args[1])
+		if !cmd.Flags().Changed("git-proto") {
+			if cfg, _, _ := loadConfig(); cfg.GitProtocol != "" {
+				dupProto = cfg.GitProtocol
+			}
+		}
+		if dupProto != "ssh" && dupProto != "https" {
+			dupProto = "https"
+		}
+		if dupCopyCode && dupTemplate != "" {
+			return fmt.Errorf("--copy-code and --template cannot be combined")
+		}
+
+		api, err := newGitLabClient()
+		if err != nil {
+			return err
+		}
+		srcs, err := resolveExportGroups(ctx, api, args[:1])
+		if err != nil {
+			return err
+		}
+		src := srcs[0]
+
+		var p *applyGroupPlan
+		err = RunSpinner(fmt.Sprintf("Reading %s from GitLab", src.Name), func() error {
+			p, err = planDuplicate(ctx, api, src, newName)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		p.Print(os.Stdout)
+		if dupCopyCode {
+			fmt.Printf("  %s%-8s default branch of every source project, as one commit%s\n", Cyan, "[COPY]", Reset)
+		}
+		if dupTemplate != "" {
+			fmt.Printf("  %s%-8s every new project from template %s%s\n", Cyan, "[SEED]", dupTemplate, Reset)
+		}
+		if dupDryRun {
+			fmt.Printf("\n%s[DRY-RUN] No changes made.%s\n", Yellow, Reset)
+			return nil
+		}
+
+		// Load the template before creating anything, so a bad one fails early
+		var tpl *projectTemplate
+		if dupTemplate != "" {
+			if tpl, err = loadTemplate(ctx, dupTemplate); err != nil {
+				return err
+			}
+			defer tpl.cleanup()
+		}
+
+		// The top-level group first, so the local folder is scaffolded like
+		// `ash group create` does; apply then fills in the tree.
+		created, err := api.CreateGroup(ctx, gitlab.CreateGroupOptions{
+			Name:        newName,
+			Path:        p.Spec.slug(),
+			Visibility:  p.Visibility,
+			Description: p.Spec.Description,
+		})
+		if err != nil {
+			return fmt.Errorf("create group failed: %w", err)
+		}
+		p.Remote = created
+		if err := fetchAndSaveGroups(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[WARN] Resync config failed: %v%s\n", Yellow, err, Reset)
+		}
+		if err := scaffoldLocalGroup(p.Dir, GitLabGroup{ID: created.ID, Name: created.Name, Path: created.Path}); err != nil {
+			return err
+		}
+
+		results := []TaskResult{{Name: newName, Status: "NEW", Message: "Created"}}
+		applyGroup(ctx, api, p, 0, &results)
+		switch {
+		case ctx.Err() != nil:
+		case dupCopyCode:
+			copyStarterCode(ctx, api, src, p, &results)
+		case tpl != nil:
+			seedFromTemplate(ctx, tpl, p, &results)
+		}
+		PrintResults(results)
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		failed := 0
+		for _, r := range results {
+			if r.Status == "ERR" {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d step(s) failed; fix the cause and run 'ash export' + 'ash apply' to complete the group", failed)
+		}
+		fmt.Printf("%s[OK] Duplicated into %s. Run 'ash group sync' there to clone the projects.%s\n", Green, p.Dir, Reset)
+		return nil
+	},
+}
+
+func init() {
+	groupCmd.AddCommand(groupDuplicateCmd)
+	groupDuplicateCmd.Flags().BoolVar(&dupCopyCode, "copy-code", false, "Push each source project's default branch to its copy as starter code")
+	groupDuplicateCmd.Flags().StringVar(&dupTemplate, "template", "", "Seed every new project from this template (name, folder or git URL)")
+	groupDuplicateCmd.Flags().BoolVar(&dupDryRun, "dry-run", false, "Print what would be created without changing anything")
+	groupDuplicateCmd.Flags().StringVar(&dupProto, "git-proto", "https", "Protocol used by --copy-code and --template (ssh|https)")
+}
+
+// planDuplicate plans the new group newName as a copy of src, through the
+// same manifest form `ash export` writes.
+func planDuplicate(ctx context.Context, api gitlab.API, src *glGroup, newName string) (*applyGroupPlan, error) {
+	spec, err := exportRemoteGroup(ctx, api, src, "")
+	if err != nil {
+		return nil, err
+	}
+	spec.Name, spec.Path = newName, ""
+	m := manifest{Groups: []manifestGroup{spec}}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	spec = m.Groups[0]
+
+	existing, err := api.GetGroupByPath(ctx, spec.slug())
+	switch {
+	case err == nil:
+		return nil, fmt.Errorf("group %q (path %s) already exists; use 'ash export' and 'ash apply' to update it", existing.Name, existing.Path)
+	case !gitlab.IsNotFound(err):
+		return nil, fmt.Errorf("look up group %s: %w", spec.slug(), err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(wd, defaultFolder(newName, spec.slug()))
+	if fileExists(dir) {
+		return nil, fmt.Errorf("folder %q already exists", dir)
+	}
+	return planApplyGroup(ctx, api, spec, nil, newName, dir, "", "public", true)
+}
+
+// --- STARTER CODE ---
+
+// copyStarterCode pushes the default branch content of every project below
+// src to its copy in p through gitPool(). Copies are matched to their source
+// the way apply matches manifest entries, so paths made by an older ash
+// still match.
+func copyStarterCode(ctx context.Context, api gitlab.API, src *glGroup, p *applyGroupPlan, results *[]TaskResult) {
+	tree, err := collectSourceTree(ctx, api, src.ID)
+	if err != nil {
+		*results = append(*results, TaskResult{Name: p.Label, Status: "ERR", Message: fmt.Sprintf("List source projects failed: %v", err)})
+		return
+	}
+	pairs, missing := starterPairs(p, tree)
+	for _, pp := range missing {
+		*results = append(*results, TaskResult{Name: pp.Label, Status: "ERR", Message: "Source project not found, starter code not copied"})
+	}
+
+	from := make(map[*applyProjectPlan]glProject, len(pairs))
+	var pps []*applyProjectPlan
+	for _, pair := range pairs {
+		from[pair.To] = pair.From
+		pps = append(pps, pair.To)
+	}
+	fillProjects(ctx, pps, func(pp *applyProjectPlan) TaskResult {
+		return pushStarterCode(ctx, from[pp], *pp.Remote)
+	}, results)
+}
+
+// fillProjects runs fill for every project in pps through gitPool() and
+// records the results under the project labels.
+func fillProjects(ctx context.Context, pps []*applyProjectPlan, fill func(pp *applyProjectPlan) TaskResult, results *[]TaskResult) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	workers := gitPool()
+	for _, pp := range pps {
+		wg.Add(1)
+		go func(pp *applyProjectPlan) {
+			defer wg.Done()
+			var res TaskResult
+			if workers.acquire(ctx) {
+				res = fill(pp)
+				workers.release()
+			} else {
+				res = cancelledResult("")
+			}
+			res.Name = pp.Label
+			mu.Lock()
+			*results = append(*results, res)
+			mu.Unlock()
+		}(pp)
+	}
+	wg.Wait()
+}
+
+// sourceTree is a source group with every project and subgroup below it.
+type sourceTree struct {
+	Path      string
+	Projects  []glProject
+	Subgroups []*sourceTree
+}
+
+// collectSourceTree lists group id and everything below it.
+func collectSourceTree(ctx context.Context, api gitlab.API, id int64) (*sourceTree, error) {
+	t := &sourceTree{}
+	var err error
+	if t.Projects, err = api.ListGroupProjects(ctx, id); err != nil {
+		return nil, err
+	}
+	sgs, err := api.ListSubgroups(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, sg := range sgs {
+		if sg.MarkedForDeletionOn != "" {
+			continue
+		}
+		child, err := collectSourceTree(ctx, api, sg.ID)
+		if err != nil {
+			return nil, err
+		}
+		child.Path = sg.Path
+		t.Subgroups = append(t.Subgroups, child)
+	}
+	return t, nil
+}
+
+// starterPair is a created project and the source project it copies.
+type starterPair struct {
+	To   *applyProjectPlan
+	From glProject
+}
+
+// starterPairs matches every created project of p to its source in src.
+// Projects whose creation failed are left out (already reported); created
+// ones without a source are returned in missing.
+func starterPairs(p *applyGroupPlan, src *sourceTree) (pairs []starterPair, missing []*applyProjectPlan) {
+	for _, pp := range p.Projects {
+		if pp.Remote == nil {
+			continue
+		}
+		var from *glProject
+		if src != nil {
+			for i := range src.Projects {
+				if pp.Spec.matches(src.Projects[i].Path) {
+					from = &src.Projects[i]
+					break
+				}
+			}
+		}
+		if from == nil {
+			missing = append(missing, pp)
+			continue
+		}
+		pairs = append(pairs, starterPair{To: pp, From: *from})
+	}
+	for _, sg := range p.Subgroups {
+		if sg.Remote == nil {
+			continue
+		}
+		var child *sourceTree
+		if src != nil {
+			for _, c := range src.Subgroups {
+				if sg.Spec.matches(c.Path) {
+					child = c
+					break
+				}
+			}
+		}
+		more, lost := starterPairs(sg, child)
+		pairs, missing = append(pairs, more...), append(missing, lost...)
+	}
+	return pairs, missing
+}
+
+// pushStarterCode copies the default branch content of from into the empty
+// project to as a single commit.
+func pushStarterCode(ctx context.Context, from, to glProject) TaskResult {
+	srcURL, dstURL := from.HTTPURLToRepo, to.HTTPURLToRepo
+	if dupProto == "ssh" {
+		srcURL, dstURL = from.SSHURLToRepo, to.SSHURLToRepo
+	}
+	branch := from.DefaultBranch
+	if branch == "" {
+		branch = "main"
+	}
+
+	tmp, err := os.MkdirTemp("", "ash-starter-")
+	if err != nil {
+		return TaskResult{Status: "ERR", Message: err.Error()}
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "repo")
+
+	fail := func(step string, err error, out []byte) TaskResult {
+		if ctx.Err() != nil {
+			return cancelledResult("")
+		}
+		msg := fmt.Sprintf("%s failed: %v", step, err)
+		if s := strings.TrimSpace(string(out)); s != "" {
+			msg += ": " + s
+		}
+		return TaskResult{Status: "ERR", Message: msg}
+	}
+
+	if out, err := gitCmd(ctx, "clone", "--quiet", "--depth", "1", srcURL, dir).CombinedOutput(); err != nil {
+		return fail("Clone source", err, out)
+	}
+	if gitCmd(ctx, "-C", dir, "rev-parse", "--verify", "--quiet", "HEAD").Run() != nil {
+		return TaskResult{Status: "SKIP", Message: "Source project is empty"}
+	}
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		return TaskResult{Status: "ERR", Message: err.Error()}
+	}
+
+	steps := [][]string{
+		{"init", "--quiet", "--initial-branch", branch},
+		{"add", "--all"},
+		{"commit", "--quiet", "--message", "Starter code from " + from.PathWithNamespace},
+		{"push", "--quiet", dstURL, "HEAD:refs/heads/" + branch},
+	}
+	for _, args := range steps {
+		if out, err := gitCmd(ctx, append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			return fail("git "+args[0], err, out)
+		}
+	}
+	return TaskResult{Status: "OK", Message: fmt.Sprintf("Starter code copied from %s (%s)", from.PathWithNamespace, branch)}
+}
+
+// --- TEMPLATE ---
+
+// seedFromTemplate seeds every project created below p from tpl.
+func seedFromTemplate(ctx context.Context, tpl *projectTemplate, p *applyGroupPlan, results *[]TaskResult) {
+	group := make(map[*applyProjectPlan]string)
+	var pps []*applyProjectPlan
+	var walk func(g *applyGroupPlan)
+	walk = func(g *applyGroupPlan) {
+		for _, pp := range g.Projects {
+			if pp.Remote != nil { // else creation failed, already reported
+				group[pp] = g.Spec.Name
+				pps = append(pps, pp)
+			}
+		}
+		for _, sg := range g.Subgroups {
+			if sg.Remote != nil {
+				walk(sg)
+			}
+		}
+	}
+	walk(p)
+	fillProjects(ctx, pps, func(pp *applyProjectPlan) TaskResult {
+		return pushTemplateCode(ctx, tpl, *pp.Remote, pp.Spec.Name, group[pp])
+	}, results)
+}
+
+// pushTemplateCode clones the empty project to and seeds it from tpl, with
+// name and group for the placeholders.
+func pushTemplateCode(ctx context.Context, tpl *projectTemplate, to glProject, name, group string) TaskResult {
+	dstURL := to.HTTPURLToRepo
+	if dupProto == "ssh" {
+		dstURL = to.SSHURLToRepo
+	}
+	tmp, err := os.MkdirTemp("", "ash-seed-")
+	if err != nil {
+		return TaskResult{Status: "ERR", Message: err.Error()}
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "repo")
+
+	if out, err := gitCmd(ctx, "clone", "--quiet", dstURL, dir).CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return cancelledResult("")
+		}
+		return TaskResult{Status: "ERR", Message: fmt.Sprintf("Clone failed: %v: %s", err, strings.TrimSpace(string(out)))}
+	}
+	vars := map[string]string{"name": name, "path": to.Path, "group": group}
+	if err := tpl.seed(ctx, dir, vars); err != nil {
+		if ctx.Err() != nil {
+			return cancelledResult("")
+		}
+		return TaskResult{Status: "ERR", Message: fmt.Sprintf("Template failed: %v", err)}
+	}
+	return TaskResult{Status: "OK", Message: "Seeded from template " + tpl.Spec}
+}
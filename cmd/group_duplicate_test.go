@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStarterPairsLegacyPaths(t *testing.T) {
+	// Source made by an older ash, with dashes for every accented letter
+	src := &sourceTree{
+		Projects: []glProject{{ID: 1, Name: "Bài Tập 1", Path: "b-i-t-p-1"}},
+		Subgroups: []*sourceTree{{
+			Path:     "bu-i-h-c-1",
+			Projects: []glProject{{ID: 2, Name: "Lab", Path: "lab"}, {ID: 3, Name: "Đề thi", Path: "exam"}},
+		}},
+	}
+	lab1 := &applyProjectPlan{Spec: manifestProject{Name: "Bài Tập 1"}, Label: "New/Bài Tập 1", Remote: &glProject{ID: 101}}
+	lab := &applyProjectPlan{Spec: manifestProject{Name: "Lab"}, Label: "New/Buổi học 1/Lab", Remote: &glProject{ID: 102}}
+	exam := &applyProjectPlan{Spec: manifestProject{Name: "Đề thi", Path: "exam"}, Label: "New/Buổi học 1/Đề thi", Remote: &glProject{ID: 103}}
+	failed := &applyProjectPlan{Spec: manifestProject{Name: "Lab 9"}, Label: "New/Lab 9"}
+	lost := &applyProjectPlan{Spec: manifestProject{Name: "Lab 8"}, Label: "New/Lab 8", Remote: &glProject{ID: 104}}
+	p := &applyGroupPlan{
+		Label:    "New",
+		Projects: []*applyProjectPlan{lab1, failed, lost},
+		Subgroups: []*applyGroupPlan{{
+			Spec:     manifestGroup{Name: "Buổi học 1"},
+			Label:    "New/Buổi học 1",
+			Remote:   &glGroup{ID: 110},
+			Projects: []*applyProjectPlan{lab, exam},
+		}},
+	}
+
+	pairs, missing := starterPairs(p, src)
+	got := make(map[*applyProjectPlan]int64)
+	for _, pair := range pairs {
+		got[pair.To] = pair.From.ID
+	}
+	want := map[*applyProjectPlan]int64{lab1: 1, lab: 2, exam: 3}
+	if len(got) != len(want) {
+		t.Errorf("got %d pairs, want %d", len(got), len(want))
+	}
+	for pp, id := range want {
+		if got[pp] != id {
+			t.Errorf("%s copies source %d, want %d", pp.Label, got[pp], id)
+		}
+	}
+	if len(missing) != 1 || missing[0] != lost {
+		t.Errorf("missing = %v, want only Lab 8", missing)
+	}
+}
+
+func TestPlanDuplicate(t *testing.T) {
+	isolateConfig(t)
+	wd := t.TempDir()
+	t.Chdir(wd)
+
+	listing := map[string]string{
+		"10/subgroups": `[{"id": 11, "name": "Buổi 1", "path": "bu-i-1", "visibility": "public"}]`,
+		"10/projects":  `[{"id": 20, "name": "Syllabus", "path": "syllabus", "visibility": "public"}]`,
+		"11/subgroups": `[]`,
+		"11/projects": `[{"id": 21, "name": "Bài Tập 1", "path": "b-i-t-p-1", "visibility": "public", "default_branch": "main"},
+			{"id": 22, "name": "Lab 2", "path": "lab2-old", "visibility": "private"}]`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/groups/{id}/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := listing[r.PathValue("id")+"/"+r.PathValue("kind")]
+		if !ok {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			body = `[]`
+		}
+		w.Write([]byte(body))
+	})
+	mux.HandleFunc("GET /api/v4/groups/{path}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "404 Group Not Found"}`))
+	})
+	standInGitLab(t, mux)
+	api, err := newGitLabClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &glGroup{ID: 10, Name: "CNTT2 - Spring 2025", Path: "cntt2-spring-2025", Visibility: "public"}
+
+	p, err := planDuplicate(t.Context(), api, src, "CNTT3 - Fall 2025")
+	if err != nil {
+		t.Fatalf("planDuplicate: %v", err)
+	}
+
+	// The structure, with paths derived anew from the names
+	if p.Remote != nil || p.Spec.slug() != "cntt3-fall-2025" || p.Dir != filepath.Join(wd, "CNTT3 - Fall 2025") {
+		t.Errorf("group: remote %v, path %q, dir %q", p.Remote, p.Spec.slug(), p.Dir)
+	}
+	if len(p.Projects) != 1 || p.Projects[0].Spec.Name != "Syllabus" {
+		t.Fatalf("group projects = %+v, want Syllabus", p.Projects)
+	}
+	if len(p.Subgroups) != 1 || p.Subgroups[0].Spec.slug() != "buoi-1" {
+		t.Fatalf("subgroups = %+v, want Buổi 1 at buoi-1", p.Subgroups)
+	}
+	sg := p.Subgroups[0]
+	want := []struct{ name, path, vis, branch string }{
+		{"Bài Tập 1", "bai-tap-1", "public", "main"},
+		{"Lab 2", "lab2-old", "private", ""}, // a custom path is kept
+	}
+	if len(sg.Projects) != len(want) {
+		t.Fatalf("Buổi 1 has %d projects, want %d", len(sg.Projects), len(want))
+	}
+	for i, w := range want {
+		pp := sg.Projects[i]
+		if pp.Remote != nil || pp.Spec.Name != w.name || pp.Spec.slug() != w.path || projectVisibility(pp, sg) != w.vis || pp.Spec.DefaultBranch != w.branch {
+			t.Errorf("project %d = %+v (visibility %s), want %+v", i, pp.Spec, projectVisibility(pp, sg), w)
+		}
+	}
+
+	// Once created, every copy finds its source
+	id := int64(100)
+	var create func(g *applyGroupPlan)
+	create = func(g *applyGroupPlan) {
+		id++
+		g.Remote = &glGroup{ID: id, Name: g.Spec.Name, Path: g.Spec.slug()}
+		for _, pp := range g.Projects {
+			id++
+			pp.Remote = &glProject{ID: id, Name: pp.Spec.Name, Path: pp.Spec.slug()}
+		}
+		for _, sg := range g.Subgroups {
+			create(sg)
+		}
+	}
+	create(p)
+	tree, err := collectSourceTree(t.Context(), api, src.ID)
+	if err != nil {
+		t.Fatalf("collectSourceTree: %v", err)
+	}
+	pairs, missing := starterPairs(p, tree)
+	got := make(map[string]int64)
+	for _, pair := range pairs {
+		got[pair.To.Spec.Name] = pair.From.ID
+	}
+	for name, id := range map[string]int64{"Syllabus": 20, "Bài Tập 1": 21, "Lab 2": 22} {
+		if got[name] != id {
+			t.Errorf("%s copies source %d, want %d", name, got[name], id)
+		}
+	}
+	if len(missing) != 0 {
+		t.Errorf("%d project(s) without a source", len(missing))
+	}
+}
+
+func TestPushTemplateCode(t *testing.T) {
+	isolateGit(t)
+	root := t.TempDir()
+	remote := filepath.Join(root, "lab1.git")
+	git(t, root, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	tplDir := filepath.Join(root, "tpl")
+	if err := os.MkdirAll(filepath.Join(tplDir, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(tplDir, "README.md"), "# {{name}} ({{group}})\n")
+	writeFile(t, filepath.Join(tplDir, "src", "Main.java"), "class Main {}\n")
+
+	tpl := &projectTemplate{Spec: "java-lab", Dir: tplDir}
+	to := glProject{ID: 5, Name: "Bài Tập 1", Path: "bai-tap-1", HTTPURLToRepo: remote}
+	if res := pushTemplateCode(t.Context(), tpl, to, "Bài Tập 1", "Buổi 1"); res.Status != "OK" {
+		t.Fatalf("pushTemplateCode = %+v", res)
+	}
+	if got := git(t, remote, "show", "main:README.md"); got != "# Bài Tập 1 (Buổi 1)" {
+		t.Errorf("README.md = %q", got)
+	}
+	if got := git(t, remote, "ls-tree", "-r", "--name-only", "main"); got != "README.md\nsrc/Main.java" {
+		t.Errorf("files = %q", got)
+	}
+}
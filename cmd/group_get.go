@@ -1,13 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 var groupGetCmd = &cobra.Command{
@@ -19,47 +17,40 @@ var groupGetCmd = &cobra.Command{
 	SilenceErrors: true,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return fetchAndSaveGroups()
+		return fetchAndSaveGroups(cmd.Context())
 	},
 }
 
-func fetchAndSaveGroups() error {
-	// Execute the glab command (requires prior `glab auth login`)
-	glabCmd := exec.Command("glab", "api", "groups?owned=true&top_level_only=true", "--paginate")
-	out, err := glabCmd.Output()
+func fetchAndSaveGroups(ctx context.Context) error {
+	api, err := newGitLabClient()
 	if err != nil {
-		return fmt.Errorf("failed to execute glab: %w", err)
+		return err
 	}
 
-	// Parse JSON response -> []GitLabGroup
-	var groups []GitLabGroup
-	if err := json.Unmarshal(out, &groups); err != nil {
-		return fmt.Errorf("failed to parse glab output: %w", err)
+	// GET /groups?owned=true&top_level_only=true
+	glGroups, err := api.ListGroups(ctx, gitlab.ListGroupsOptions{Owned: true, TopLevelOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to fetch groups: %w", err)
 	}
 
 	// Handle empty result gracefully
-	if len(groups) == 0 {
+	if len(glGroups) == 0 {
 		fmt.Println("No owned top-level groups found.")
 		return nil
 	}
 
-	// Resolve config path: ~/.config/ash/config.json
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return fmt.Errorf("failed to get user config dir: %w", err)
-	}
-	ashDir := filepath.Join(configDir, "ash")
-	configPath := filepath.Join(ashDir, "config.json")
-
-	// Ensure directory exists
-	if err := os.MkdirAll(ashDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create config dir: %w", err)
+	groups := make([]GitLabGroup, 0, len(glGroups))
+	for _, g := range glGroups {
+		groups = append(groups, GitLabGroup{ID: g.ID, Name: g.Name, Path: g.Path})
 	}
 
-	// Write pretty JSON to config.json
-	buf, _ := json.MarshalIndent(AshConfig{Groups: groups}, "", "  ")
-	if err := os.WriteFile(configPath, buf, 0o644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	// Update only the group list; keep login and protocol settings
+	configPath, err := updateConfig(func(cfg *AshConfig) error {
+		cfg.Groups = groups
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update config file: %w", err)
 	}
 
 	fmt.Printf("%s Saved %d groups to %s\n", icOk, len(groups), configPath)
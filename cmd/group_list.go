@@ -45,6 +45,13 @@ func showSavedGroups() error {
 		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	if structuredOutput() {
+		if cfg.Groups == nil {
+			cfg.Groups = []GitLabGroup{}
+		}
+		return writeStructured(cfg.Groups)
+	}
+
 	// Empty case
 	if len(cfg.Groups) == 0 {
 		fmt.Println("No groups saved in config.")
@@ -1,18 +1,20 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	groupSyncClean bool
+	groupSyncClean  bool
+	groupSyncDryRun bool
 )
 
 var groupSyncCmd = &cobra.Command{
@@ -20,385 +22,365 @@ var groupSyncCmd = &cobra.Command{
 	Short: "Sync the current group (metadata + all subgroups)",
 	Long: `Sync the current group's metadata and recursively sync all its subgroups.
 This means:
-1. Fetch the latest list of subgroups from GitLab.
+1. Fetch the latest list of subgroups and projects from GitLab.
 2. Update the local .ash/group.json file (handle additions, removals, renames).
-3. If --clean is used, delete local folders of subgroups that no longer exist on GitLab.
-4. Recursively run 'sync' on every subgroup (which pulls code for all projects).`,
+3. If --clean is used, move local folders of subgroups or projects that no longer
+   exist on GitLab to the trash (see 'ash trash').
+   Folders holding uncommitted, unpushed or stashed git work are only deleted
+   after confirmation (or with --discard-local-work).
+4. Clone/pull the projects of the group itself, then do the same for every
+   subgroup, at any depth.
+
+Works from any folder inside the group.
+With --dry-run, the full plan is printed and nothing is changed on disk or remotes.
+Failures (a clone, a pull, a folder rename, a subgroup) do not stop the sync;
+they are listed at the end and make the command exit with an error.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// 1. Context Check
-		wd, err := os.Getwd()
+		ws, err := currentWorkspace()
 		if err != nil {
 			return err
 		}
-		ashDir := filepath.Join(wd, ".ash")
-		groupMetaPath := filepath.Join(ashDir, "group.json")
-
-		if !fileExists(groupMetaPath) {
-			return fmt.Errorf("not in a group root (.ash/group.json missing)")
+		wd, err := ws.groupRoot()
+		if err != nil {
+			return err
 		}
+		groupMetaPath := filepath.Join(wd, ".ash", "group.json")
 
 		var meta rootGroupMeta
-		if err := readJSON(groupMetaPath, &meta); err != nil {
+		if err := readGroupMeta(groupMetaPath, &meta); err != nil {
 			return err
 		}
 
-		fmt.Printf("Syncing Group: %s (ID: %d)\n", meta.Group.Name, meta.Group.ID)
+		fmt.Fprintf(infoOut(), "Syncing Group: %s (ID: %d)\n", meta.Group.Name, meta.Group.ID)
 
-		// 2. Fetch remote subgroups
-		sgs, err := apiListSubgroups(meta.Group.ID)
+		// 2. Plan (remote subgroups and projects vs local meta and folders);
+		// a dry run plans every level up front so it can print them all.
+		plan, err := planLevelSync(cmd.Context(), wd, wd, meta.Group, meta.level(), groupSyncClean, groupSyncDryRun)
 		if err != nil {
-			return fmt.Errorf("failed to fetch subgroups: %w", err)
-		}
-
-		// Filter out Marked For Deletion groups
-		var validRemoteSGs []glGroup
-		for _, sg := range sgs {
-			// DEBUG: Print info to verify API response
-			// fmt.Printf("[DEBUG] Subgroup %s, MarkedForDeletionOn: '%s'\n", sg.Name, sg.MarkedForDeletionOn)
-
-			if sg.MarkedForDeletionOn == "" {
-				validRemoteSGs = append(validRemoteSGs, sg)
-			} else {
-				fmt.Printf("%s[INFO] Ignoring soft-deleted subgroup: %s%s\n", Yellow, sg.Name, Reset)
-			}
-		}
-
-		// 3. Diff & Update Metadata
-		// Map ID -> Remote Group
-		remoteMap := make(map[int64]glGroup)
-		for _, sg := range validRemoteSGs {
-			remoteMap[sg.ID] = sg
-		}
-
-		// Identify removed (Legacy check against old meta)
-		// var removedPaths []string // Keep for compatibility if needed, but orphan scan handles deletion better.
-		// We still need this loop to detect Renames.
-
-		var newSubgroupIdents []subgroupIdent
-		for _, sg := range validRemoteSGs {
-			newSubgroupIdents = append(newSubgroupIdents, subgroupIdent{
-				ID:   sg.ID,
-				Name: sg.Name,
-				Path: sg.Path,
-			})
-		}
-
-		// Check what was removed / Renamed
-		for _, oldSg := range meta.Subgroups {
-			if _, ok := remoteMap[oldSg.ID]; !ok {
-				// Removed or Soft-Deleted
-				// Ideally we do nothing here and let orphan scanner kill it.
-				// But we can verify.
-			} else {
-				// Still exists, check for RENAME
-				newSg := remoteMap[oldSg.ID]
-				if newSg.Name != oldSg.Name {
-					fmt.Printf("%s[INFO] Subgroup renamed: %s -> %s%s\n", Yellow, oldSg.Name, newSg.Name, Reset)
-					oldPath := filepath.Join(wd, oldSg.Name)
-					newPath := filepath.Join(wd, newSg.Name)
-					if fileExists(oldPath) {
-						if err := os.Rename(oldPath, newPath); err != nil {
-							fmt.Printf("%s[WARN] Failed to rename local folder: %v%s\n", Yellow, err, Reset)
-						} else {
-							fmt.Printf("%s[OK] Renamed local folder.%s\n", Green, Reset)
-						}
-					}
-				}
-			}
-		}
-
-		// Save new meta
-		meta.Subgroups = newSubgroupIdents
-
-		// FIX: Check if root group details are missing and update them
-		if meta.Group.Name == "" || meta.Group.Path == "" {
-			url := fmt.Sprintf("groups/%d", meta.Group.ID)
-			out, err := exec.Command("glab", "api", url).Output()
-			if err == nil {
-				var info glGroup
-				if json.Unmarshal(out, &info) == nil {
-					meta.Group.Name = info.Name
-					meta.Group.Path = info.Path
-				}
-			}
-		}
-		if err := writeGroupJSON(ashDir, meta); err != nil {
-			return fmt.Errorf("failed to write updated metadata: %w", err)
-		}
-		fmt.Printf("%s[OK] Metadata updated. %d subgroups found.%s\n", Green, len(newSubgroupIdents), Reset)
-
-		// 4. Handle Cleanup (ROBUST ORPHAN SCAN)
-		// Scan local folders that are NOT in the new list.
-		if groupSyncClean {
-			validNames := make(map[string]bool)
-			for _, sg := range newSubgroupIdents {
-				validNames[sg.Name] = true
-			}
-
-			entries, _ := os.ReadDir(wd)
-			for _, e := range entries {
-				if !e.IsDir() {
-					continue
-				}
-				name := e.Name()
-				if name == ".ash" || name == ".git" || name == "." || name == ".." {
-					continue
-				}
-
-				if !validNames[name] {
-					// Orphan
-					if err := os.RemoveAll(filepath.Join(wd, name)); err != nil {
-						fmt.Printf("%s[ERR] Failed to remove orphan subgroup %s: %v%s\n", Red, name, err, Reset)
-					} else {
-						fmt.Printf("%s[DEL] Removed orphan subgroup: %s%s\n", Red, name, Reset)
-					}
-				}
-			}
+			return err
 		}
 
-		// 5. Recursive Sync (Projects inside subgroups)
-		// For each subgroup, we need to run the equivalent of "ash subgroup sync"
-		// We can't easily import `subgroupSyncCmd.Run`, but we can extract a helper function.
-		// For now, let's implement the logic or call a reusable function.
-		// Since we haven't refactored Subgroup Sync yet, let's create a shared helper key logic.
-
-		fmt.Println("Recursively syncing subgroups...")
-		var wg sync.WaitGroup
-		sem := make(chan struct{}, 3) // Limit concurrency
-
-		for _, sgIdent := range newSubgroupIdents {
-			// Compute local path (Name is used for folder)
-			sgDir := filepath.Join(wd, sgIdent.Name)
-
-			// If folder doesn't exist, Create it (Scaffold)
-			if !fileExists(sgDir) {
-				if err := os.MkdirAll(sgDir, 0o755); err != nil {
-					fmt.Printf("[ERR] Failed to create folder %s\n", sgIdent.Name)
-					continue
-				}
-				// create .ash/subgroup.json
-				emptyMeta := subgroupMeta{
-					Group:    groupIdent{ID: sgIdent.ID, Path: sgIdent.Path, Name: sgIdent.Name},
-					Projects: []projectIdent{},
-				}
-				writeSubgroupJSON(filepath.Join(sgDir, ".ash"), emptyMeta)
-			}
-
-			wg.Add(1)
-			go func(dir string, id int64) {
-				defer wg.Done()
-				sem <- struct{}{}
-				defer func() { <-sem }()
-
-				// Execute Sync logic for this subgroup
-				// We can re-use the logic that will be in `subgroup_sync`
-				// For now calling a placeholder `syncSubgroupContent(dir, id)`
-				if err := syncSubgroupContent(dir, id, groupSyncClean); err != nil {
-					fmt.Printf("[ERR] Sync %s failed: %v\n", dir, err)
-				}
-			}(sgDir, sgIdent.ID)
+		if groupSyncDryRun {
+			fmt.Fprintln(infoOut())
+			plan.Print()
+			fmt.Fprintf(infoOut(), "\n%s[DRY-RUN] No changes made.%s\n", Yellow, Reset)
+			return nil
 		}
-		wg.Wait()
 
-		return nil
+		fails := newSyncFailures(wd)
+		err = applyLevelSync(cmd.Context(), plan, fails)
+		return fails.report(err)
 	},
 }
 
 func init() {
 	groupCmd.AddCommand(groupSyncCmd)
-	groupSyncCmd.Flags().BoolVar(&groupSyncClean, "clean", false, "Delete local folders of removed subgroups")
+	groupSyncCmd.Flags().BoolVar(&groupSyncClean, "clean", false, "Move local folders of removed subgroups and projects to the trash")
+	groupSyncCmd.Flags().BoolVar(&groupSyncDryRun, "dry-run", false, "Print the sync plan without changing anything")
+	groupSyncCmd.Flags().BoolVar(&discardLocalWork, "discard-local-work", false, "With --clean, delete orphan folders even if they hold uncommitted or unpushed work")
 }
 
-// syncSubgroupContent executes sync logic for one subgroup (fetch projects -> clone/pull)
-// This function is shared by `ash group sync` and `ash subgroup sync`
-func syncSubgroupContent(wd string, subgroupID int64, clean bool) error {
-	// 1. Fetch Projects
-	prjs, err := apiListProjects(subgroupID)
-	if err != nil {
-		return err
-	}
-
-	// 2. Read/Update Local Meta
-	ashDir := filepath.Join(wd, ".ash")
-	subMetaPath := filepath.Join(ashDir, "subgroup.json")
-
-	var meta subgroupMeta
-	// Try read existing
-	_ = readJSON(subMetaPath, &meta)
-
-	// Update Group info (just in case ID matched but passed explicitly)
-	// meta.Group.ID = subgroupID (keep what we have or update?)
-
-	oldPrjs := meta.Projects
-	remoteMap := make(map[int64]glProject)
-	for _, p := range prjs {
-		remoteMap[p.ID] = p
+// applyLevelSync executes a level plan, then syncs every nested subgroup:
+// renames, orphan cleanup, scaffolding, clone/pull, then metadata. On
+// cancellation, projects whose clone did not complete are left out of the
+// metadata (the next sync clones them).
+// Failures that leave the rest of the sync possible are collected in fails;
+// the returned error is for the ones that stop this level.
+// This function is shared by `ash group sync` and `ash subgroup sync`.
+func applyLevelSync(ctx context.Context, plan *levelSyncPlan, fails *syncFailures) error {
+	wd := plan.Dir
+
+	for _, name := range plan.Ignored {
+		fmt.Fprintf(infoOut(), "%s[INFO] Ignoring soft-deleted subgroup: %s%s\n", Yellow, name, Reset)
 	}
 
-	// Detect Removed
-	var removedNames []string
-	for _, old := range oldPrjs {
-		if _, ok := remoteMap[old.ID]; !ok {
-			removedNames = append(removedNames, old.Name)
+	// 1. Renames (the parent has already moved this folder to wd). A folder
+	// that could not be renamed stays recorded under its old name.
+	for _, r := range plan.SubgroupRenames {
+		fmt.Fprintf(infoOut(), "%s[INFO] Subgroup renamed: %s -> %s%s\n", Yellow, r.From, r.To, Reset)
+		if err := os.Rename(filepath.Join(wd, r.From), filepath.Join(wd, r.To)); err != nil {
+			fmt.Fprintf(infoOut(), "%s[ERR] Failed to rename local folder: %v%s\n", Red, err, Reset)
+			fails.add(filepath.Join(wd, r.From), fmt.Sprintf("Rename to %q failed: %v", r.To, err))
+			plan.keepSubgroupFolder(r)
 		} else {
-			// Check rename?
-			// Doing rename for projects is tricky if folder names change.
-			newP := remoteMap[old.ID]
-			if newP.Name != old.Name {
-				// Try rename folder
-				oldPath := filepath.Join(wd, old.Name)
-				newPath := filepath.Join(wd, newP.Name)
-				if fileExists(oldPath) {
-					os.Rename(oldPath, newPath)
-				}
-			}
+			fmt.Fprintf(infoOut(), "%s[OK] Renamed local folder.%s\n", Green, Reset)
+		}
+	}
+	for _, r := range plan.Renames {
+		if err := os.Rename(filepath.Join(wd, r.From), filepath.Join(wd, r.To)); err != nil {
+			fmt.Fprintf(infoOut(), "%s[ERR] Failed to rename %s to %s: %v%s\n", Red, r.From, r.To, err, Reset)
+			fails.add(filepath.Join(wd, r.From), fmt.Sprintf("Rename to %q failed: %v", r.To, err))
+			plan.keepProjectFolder(r)
 		}
 	}
 
-	// Save new List
-	newidents := []projectIdent{}
-	for _, p := range prjs {
-		newidents = append(newidents, projectIdent{ID: p.ID, Name: p.Name, Path: p.Path})
+	// Nothing below has started yet: leave the metadata alone.
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	meta.Projects = newidents
-
-	// FIX: Update group details from fetch (since we only have ID sometimes)
-	// Theoretically we could fetch the Group info again, but here we only listed Projects.
-	// However, we should at least preserve existing Name/Path if they are not empty.
-	// If they are empty (which caused the issue), we might need to fetch them.
-	// BUT, `syncSubgroupContent` takes `subgroupID`.
-	// We didn't fetch the Group Info here!
-	// We only fetched projects.
-	// To fix the issue "path and name ... are empty", we need to fetch the Group info if missing.
-	if meta.Group.Name == "" || meta.Group.Path == "" {
-		// Fetch Group Details
-		// glab api groups/:id
-		url := fmt.Sprintf("groups/%d", subgroupID)
-		out, err := exec.Command("glab", "api", url).Output()
-		if err == nil {
-			var gDetails glGroup
-			if json.Unmarshal(out, &gDetails) == nil {
-				meta.Group.Name = gDetails.Name
-				meta.Group.Path = gDetails.Path
+
+	// 2. Clean / Orphan Check (ROBUST ORPHAN SCAN)
+	for _, name := range plan.Orphans {
+		if plan.Clean {
+			if ctx.Err() != nil {
+				break
 			}
+			dir := filepath.Join(wd, name)
+			if err := checkLocalWork(ctx, dir); err != nil {
+				fmt.Fprintf(infoOut(), "%s[SKIP] %v%s\n", Yellow, err, Reset)
+				continue
+			}
+			if err := removeDir(dir, plan.OrphanKind[name], plan.OrphanIDs[name], name); err != nil {
+				fmt.Fprintf(infoOut(), "%s[ERR] Failed to remove orphan %s: %v%s\n", Red, name, err, Reset)
+				fails.add(dir, fmt.Sprintf("Removing orphan folder failed: %v", err))
+			}
+		} else {
+			fmt.Fprintf(infoOut(), "%s[INFO] Found orphan folder: %s (use --clean to remove)%s\n", Yellow, name, Reset)
 		}
 	}
-	// Also ensure ID is set.
-	if meta.Group.ID == 0 {
-		meta.Group.ID = subgroupID
-	}
 
-	writeSubgroupJSON(ashDir, meta)
-
-	// Clean / Orphan Check
-	// Instead of just relying on `removedNames` (which depends on old metadata),
-	// we scan the local directory for ANY folder that is NOT in the new project list.
-	// This covers cases where metadata was already updated but folders weren't deleted.
-
-	validNames := make(map[string]bool)
-	for _, p := range newidents {
-		validNames[p.Name] = true
+	for _, name := range plan.Skips {
+		fmt.Fprintf(infoOut(), "%s[SKIP] %s (folder exists but not git repo)%s\n", Yellow, name, Reset)
 	}
 
-	entries, _ := os.ReadDir(wd)
-	for _, e := range entries {
-		if !e.IsDir() {
+	// 3. If a subgroup folder doesn't exist, Create it (Scaffold)
+	for _, sgIdent := range plan.Scaffold {
+		sgDir := filepath.Join(wd, sgIdent.Dir)
+		if err := os.MkdirAll(sgDir, 0o755); err != nil {
+			fmt.Fprintf(infoOut(), "%s[ERR] Failed to create folder %s%s\n", Red, sgIdent.Dir, Reset)
+			fails.add(sgDir, fmt.Sprintf("Creating folder failed: %v", err))
 			continue
 		}
-		name := e.Name()
-		if name == ".ash" || name == ".git" || name == "." || name == ".." {
-			continue
+		// create .ash/subgroup.json
+		emptyMeta := subgroupMeta{
+			Group:    groupIdent{ID: sgIdent.ID, Path: sgIdent.Path, Name: sgIdent.Name},
+			Projects: []projectIdent{},
 		}
+		if err := writeSubgroupJSON(filepath.Join(sgDir, ".ash"), emptyMeta); err != nil {
+			fails.add(sgDir, fmt.Sprintf("Writing subgroup.json failed: %v", err))
+		}
+	}
 
-		// If this folder is NOT in the valid list of projects
-		if !validNames[name] {
-			// It's an orphan (or a leftover from deletion)
-			if clean {
-				if err := os.RemoveAll(filepath.Join(wd, name)); err != nil {
-					fmt.Printf("%s[ERR] Failed to remove orphan %s: %v%s\n", Red, name, err, Reset)
-				} else {
-					fmt.Printf("%s[DEL] Removed orphan folder: %s%s\n", Red, name, Reset)
-				}
-			} else {
-				fmt.Printf("%s[INFO] Found orphan folder: %s (use --clean to remove)%s\n", Yellow, name, Reset)
+	// 4. Sync Code (Clone/Pull)
+	notCloned, notStarted := syncLevelRepos(ctx, plan, fails)
+
+	// 5. Save new meta
+	meta := plan.Meta
+	if len(notCloned) > 0 {
+		meta.Projects = []projectIdent{}
+		for _, p := range plan.Meta.Projects {
+			if !notCloned[p.Name] {
+				meta.Projects = append(meta.Projects, p)
 			}
 		}
 	}
+	if err := writeLevelMeta(wd, meta); err != nil {
+		return fmt.Errorf("failed to write %s metadata: %w", meta.kind(), err)
+	}
+	if meta.Root {
+		fmt.Fprintf(infoOut(), "%s[OK] Metadata updated. %d subgroups found.%s\n", Green, len(meta.Subgroups), Reset)
+	}
+
+	if notStarted > 0 {
+		fmt.Fprintf(infoOut(), "%s[CANCELLED] %d repositories in %s not synced%s\n", Yellow, notStarted, wd, Reset)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	// 3. Sync Code (Clone/Pull)
-	// Reuse `syncOneProject` from project_sync.go?
-	// Note: syncOneProject needs `projectIdent`
-	// We might need to make `syncOneProject` public or move strict logic.
-	// `syncOneProject` is in cmd/project_sync.go, it is currently exported (lowercase s? No, Uppercase S? No it's lower.)
-	// Need to export `SyncOneProject` or copy logic.
-	// Providing a Helper in a separate file is better.
-
-	// We will use a simplified inline logic or call the tool's run command?
-	// Better to have pure Go function.
-	// Let's assume we rename `syncOneProject` to `SyncOneProject` in project_sync.go later.
-	// For now, I will duplicate/adapt the simple clone/pull logic here to avoid circular dependencies if any,
-	// or ideally refactor `syncOneProject` into `helpers.go` or keep it in `project_sync.go` and export it.
-
-	// 3. Sync Code (Clone/Pull)
+	// 6. Recursive Sync (nested subgroups, any depth)
+	return syncChildLevels(ctx, plan, fails)
+}
+
+// syncLevelRepos clones and pulls the projects of one level through
+// gitPool(). Failed clones and pulls go to fails. It returns the clones cut
+// short by cancellation and the number of repositories that never got a
+// worker.
+func syncLevelRepos(ctx context.Context, plan *levelSyncPlan, fails *syncFailures) (map[string]bool, int) {
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, 5)
+	var mu sync.Mutex
+	workers := gitPool()
+	notCloned := make(map[string]bool)
+	notStarted := 0
+
+	// acquire waits for a worker slot; false once the sync is cancelled.
+	acquire := func() bool {
+		if workers.acquire(ctx) {
+			return true
+		}
+		mu.Lock()
+		notStarted++
+		mu.Unlock()
+		return false
+	}
 
-	// Determine Protocol
-	proto := "https"
-	cfg, _, _ := loadConfig()
-	if cfg.GitProtocol != "" {
-		proto = cfg.GitProtocol
+	for _, r := range plan.Clones {
+		wg.Add(1)
+		go func(repo syncRepo) {
+			defer wg.Done()
+			if !acquire() {
+				mu.Lock()
+				notCloned[repo.Name] = true
+				mu.Unlock()
+				return
+			}
+			defer workers.release()
+
+			out, err := gitCmd(ctx, "clone", "--quiet", repo.URL, repo.Dir).CombinedOutput()
+			switch {
+			case err != nil && ctx.Err() != nil:
+				os.RemoveAll(repo.Dir) // partial clone
+				mu.Lock()
+				notCloned[repo.Name] = true
+				mu.Unlock()
+				fmt.Fprintf(infoOut(), "%s[CANCELLED] Clone %s interrupted%s\n", Yellow, repo.Name, Reset)
+			case err != nil:
+				fmt.Fprintf(infoOut(), "%s[ERR] Clone %s failed: %v\n%s%s", Red, repo.Name, err, string(out), Reset)
+				fails.add(repo.Dir, "Clone failed: "+gitFailure(err, out))
+			default:
+				fmt.Fprintf(infoOut(), "%s[NEW] Cloned: %s%s\n", Cyan, repo.Name, Reset)
+			}
+		}(r)
 	}
 
-	for _, p := range newidents {
+	for _, r := range plan.Pulls {
 		wg.Add(1)
-		go func(proj projectIdent) {
+		go func(repo syncRepo) {
 			defer wg.Done()
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			// Use the remote info for URL
-			remoteP := remoteMap[proj.ID]
-			url := remoteP.HTTPURLToRepo
-			if proto == "ssh" {
-				url = remoteP.SSHURLToRepo
+			if !acquire() {
+				return
 			}
+			defer workers.release()
+
+			// Update remote URL just in case path changed
+			gitCmd(ctx, "-C", repo.Dir, "remote", "set-url", "origin", repo.URL).Run()
+
+			out, err := gitCmd(ctx, "-C", repo.Dir, "pull", "--quiet").CombinedOutput()
+			switch {
+			case err != nil && ctx.Err() != nil:
+				fmt.Fprintf(infoOut(), "%s[CANCELLED] Pull %s interrupted%s\n", Yellow, repo.Name, Reset)
+			case err != nil:
+				fmt.Fprintf(infoOut(), "%s[ERR] Pull %s failed: %v\n%s%s", Red, repo.Name, err, string(out), Reset)
+				fails.add(repo.Dir, "Pull failed: "+gitFailure(err, out))
+			default:
+				fmt.Fprintf(infoOut(), "%s[OK] Checked: %s%s\n", Green, repo.Name, Reset)
+			}
+		}(r)
+	}
+	wg.Wait()
+	return notCloned, notStarted
+}
 
-			targetDir := filepath.Join(wd, proj.Name)
-			if !fileExists(targetDir) {
-				// Clone
-				out, err := exec.Command("git", "clone", "--quiet", url, targetDir).CombinedOutput()
-				if err != nil {
-					fmt.Printf("%s[ERR] Clone %s failed: %v\n%s%s", Red, proj.Name, err, string(out), Reset)
-				} else {
-					fmt.Printf("%s[NEW] Cloned: %s%s\n", Cyan, proj.Name, Reset)
-				}
-			} else {
-				// Pull
-				if fileExists(filepath.Join(targetDir, ".git")) {
-					// Update remote URL just in case path changed
-					exec.Command("git", "-C", targetDir, "remote", "set-url", "origin", url).Run()
-
-					// Check if pull is needed? "git pull" usually prints "Already up to date" if nothing changed.
-					// We can capture output.
-					out, err := exec.Command("git", "-C", targetDir, "pull", "--quiet").CombinedOutput()
-					if err != nil {
-						fmt.Printf("%s[ERR] Pull %s failed: %v\n%s%s", Red, proj.Name, err, string(out), Reset)
-					} else {
-						// Quiet mode doesn't print anything on success.
-						// If we want to know if it updated, we might need to check HEAD before/after or remove --quiet.
-						// But for user "sync complete" feeling, maybe just print OK is enough.
-						// OR print only if updated?
-						// Let's print [OK] Updated or [OK] Checked.
-						fmt.Printf("%s[OK] Checked: %s%s\n", Green, proj.Name, Reset)
-					}
-				} else {
-					fmt.Printf("%s[SKIP] %s (folder exists but not git repo)%s\n", Yellow, proj.Name, Reset)
+// syncChildLevels syncs the nested subgroups of plan concurrently; their git
+// work shares gitPool(). Children not planned yet are planned here. A child
+// that fails is recorded in fails; the others carry on.
+func syncChildLevels(ctx context.Context, plan *levelSyncPlan, fails *syncFailures) error {
+	planned := make(map[string]*levelSyncPlan)
+	for _, child := range plan.Children {
+		planned[child.Dir] = child
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, jobCount()) // Limit concurrent API planning
+	for _, sgIdent := range plan.Meta.Subgroups {
+		// Compute local path (as recorded in the metadata)
+		sgDir := filepath.Join(plan.Dir, sgIdent.Dir)
+		if !fileExists(sgDir) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(dir string, sg subgroupIdent) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				fmt.Fprintf(infoOut(), "%s[CANCELLED] Sync %s not started%s\n", Yellow, dir, Reset)
+				return
+			}
+
+			child := planned[dir]
+			var err error
+			if child == nil {
+				child, err = planLevelSync(ctx, dir, dir, sg.group(), childMeta(dir), plan.Clean, false)
+			}
+			<-sem
+			if err == nil {
+				err = applyLevelSync(ctx, child, fails)
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					fmt.Fprintf(infoOut(), "%s[CANCELLED] Sync %s interrupted%s\n", Yellow, dir, Reset)
+					return
 				}
+				fmt.Fprintf(infoOut(), "%s[ERR] Sync %s failed: %v%s\n", Red, dir, err, Reset)
+				fails.add(dir, fmt.Sprintf("Sync failed: %v", err))
 			}
-		}(p)
+		}(sgDir, sgIdent)
 	}
 	wg.Wait()
+	return ctx.Err()
+}
+
+// --- FAILURES ---
+
+// syncFailures collects what failed across all levels of a sync, which
+// carries on past such failures; report lists them at the end.
+type syncFailures struct {
+	root string // names are shown relative to it
+
+	mu   sync.Mutex
+	list []TaskResult
+}
+
+func newSyncFailures(root string) *syncFailures {
+	return &syncFailures{root: root}
+}
+
+// add records a failure of the folder dir.
+func (f *syncFailures) add(dir, msg string) {
+	name := dir
+	if rel, err := filepath.Rel(f.root, dir); err == nil {
+		name = rel
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.list = append(f.list, TaskResult{Name: name, Status: "ERR", Message: msg})
+}
 
+// report prints the failures as ERR results and returns the error the
+// command exits with: err if the sync itself stopped, else one counting the
+// failures.
+func (f *syncFailures) report(err error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sort.Slice(f.list, func(i, j int) bool { return naturalLess(f.list[i].Name, f.list[j].Name) })
+	if len(f.list) > 0 || structuredOutput() {
+		PrintResults(f.list)
+	}
+	if err != nil {
+		return err
+	}
+	if len(f.list) > 0 {
+		return fmt.Errorf("sync finished with %d failure(s)", len(f.list))
+	}
 	return nil
 }
+
+// gitFailure describes a failed git command by its first "fatal:" or
+// "error:" line (the ones after it are hints), else by its last line.
+func gitFailure(err error, out []byte) string {
+	for _, ln := range strings.Split(string(out), "\n") {
+		ln = strings.TrimSpace(ln)
+		if strings.HasPrefix(ln, "fatal:") || strings.HasPrefix(ln, "error:") {
+			return ln
+		}
+	}
+	if l := lastLine(out); l != "" {
+		return l
+	}
+	return err.Error()
+}
@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
+
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 // --- COLORS (ANSI) ---
@@ -24,12 +27,21 @@ var (
 // --- TASK RESULTS ---
 
 type TaskResult struct {
-	Name    string // Object Name (e.g. Exercise1)
-	Status  string // OK, ERR, NEW, SKIP
-	Message string // Details (Cloned, Push failed...)
+	Name    string `json:"name" yaml:"name"`       // Object Name (e.g. Exercise1)
+	Status  string `json:"status" yaml:"status"`   // OK, ERR, NEW, SKIP, CANCELLED
+	Message string `json:"message" yaml:"message"` // Details (Cloned, Push failed...)
 }
 
 func PrintResults(results []TaskResult) {
+	if structuredOutput() {
+		if results == nil {
+			results = []TaskResult{}
+		}
+		if err := writeStructured(results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return
+	}
 	if len(results) == 0 {
 		return
 	}
@@ -49,6 +61,9 @@ func PrintResults(results []TaskResult) {
 		case "SKIP":
 			color = Yellow
 			tag = "[SKIP]"
+		case "CANCELLED":
+			color = Yellow
+			tag = "[CANCELLED]"
 		default:
 			color = Gray
 			tag = "[INFO]"
@@ -75,26 +90,74 @@ func readJSON(path string, v any) error {
 }
 
 func writeJSON(path string, v any) error {
+	return writeJSONPerm(path, v, 0o644)
+}
+
+func writeJSONPerm(path string, v any, perm os.FileMode) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b, perm)
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into
+// place, so readers (and interrupted runs) never see a truncated file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
 	}
-	b, _ := json.MarshalIndent(v, "", "  ")
-	return os.WriteFile(path, b, 0o644)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
-// Specific wrapper for writing group/subgroup json to match legacy code
+// Specific wrapper for writing group/subgroup json to match legacy code.
+// Metadata is always written in the current schema (see metadata.go), under
+// the .ash directory lock (see lock.go).
 func writeGroupJSON(ashDir string, meta rootGroupMeta) error {
+	l, err := lockMetaDir(ashDir)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	meta.normalize(filepath.Dir(ashDir))
 	return writeJSON(filepath.Join(ashDir, "group.json"), meta)
 }
 
 func writeSubgroupJSON(ashDir string, meta subgroupMeta) error {
+	l, err := lockMetaDir(ashDir)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	meta.normalize(filepath.Dir(ashDir))
 	return writeJSON(filepath.Join(ashDir, "subgroup.json"), meta)
 }
 
 // findSubgroupByPath returns (true, glGroup, nil) if a subgroup with the given slug/path exists under parentID.
-func findSubgroupByPath(parentID int64, slug string) (bool, glGroup, error) {
-	sgs, err := apiListSubgroups(parentID)
+func findSubgroupByPath(ctx context.Context, parentID int64, slug string) (bool, glGroup, error) {
+	sgs, err := apiListSubgroups(ctx, parentID)
 	if err != nil {
 		return false, glGroup{}, err
 	}
@@ -123,16 +186,15 @@ func loadConfig() (AshConfig, string, error) {
 	return cfg, cfgPath, err
 }
 
+// saveConfig writes the config with owner-only permissions since it holds the token.
+// Callers that read, change and write it back should use updateConfig instead.
 func saveConfig(path string, cfg AshConfig) error {
-	return writeJSON(path, cfg)
-}
-
-func slugify(s string) string {
-	s = strings.ToLower(strings.TrimSpace(s))
-	s = strings.ReplaceAll(s, " ", "-")
-	re := regexp.MustCompile(`[^a-z0-9-]`)
-	s = re.ReplaceAllString(s, "-")
-	return strings.Trim(s, "-")
+	l, err := lockConfigFile(path)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	return writeJSONPerm(path, cfg, 0o600)
 }
 
 // --- LOGIC HELPERS (Group/Clone) ---
@@ -169,81 +231,80 @@ func scaffoldLocalGroup(dir string, g GitLabGroup) error {
 
 // --- API / CLONE HELPERS ---
 
-func apiListProjects(groupID int64) ([]glProject, error) {
-	url := fmt.Sprintf("groups/%d/projects?per_page=100&simple=true", groupID)
-	out, err := exec.Command("glab", "api", url, "--paginate").Output()
-	if err != nil {
-		return nil, err
+const defaultGitLabHost = "git.rikkei.edu.vn"
+
+var errNotLoggedIn = errors.New("not logged in to GitLab: run 'ash auth login -t <token>' first")
+
+// gitlabBaseURL resolves the GitLab instance URL from config.
+// GITLAB_HOST (a host name or full URL) overrides the saved host.
+func gitlabBaseURL(cfg AshConfig) string {
+	if env := strings.TrimSpace(os.Getenv("GITLAB_HOST")); env != "" {
+		if strings.Contains(env, "://") {
+			return env
+		}
+		return "https://" + env
 	}
-	var prjs []glProject
-	if err := json.Unmarshal(out, &prjs); err != nil {
-		return nil, err
+	proto := cfg.APIProtocol
+	if proto == "" {
+		proto = "https"
+	}
+	host := cfg.APIHost
+	if host == "" {
+		host = cfg.Host
+	}
+	if host == "" {
+		host = defaultGitLabHost
 	}
-	return prjs, nil
+	return (&url.URL{Scheme: proto, Host: host}).String()
 }
 
-func apiListSubgroups(groupID int64) ([]glGroup, error) {
-	url := fmt.Sprintf("groups/%d/subgroups?per_page=100", groupID)
-	out, err := exec.Command("glab", "api", url, "--paginate").Output()
+// newGitLabClient builds the GitLab API client from ~/.config/ash/config.json.
+// GITLAB_TOKEN overrides the saved token.
+// It is a variable so that tests can point the commands at a stand-in server.
+var newGitLabClient = func() (gitlab.API, error) {
+	cfg, _, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	token := cfg.Token
+	if env := os.Getenv("GITLAB_TOKEN"); env != "" {
+		token = env
+	}
+	if token == "" {
+		return nil, errNotLoggedIn
+	}
+	c, err := gitlab.NewClient(gitlabBaseURL(cfg), token)
 	if err != nil {
 		return nil, err
 	}
-	var sgs []glGroup
-	if err := json.Unmarshal(out, &sgs); err != nil {
+	c.UserAgent = "ash/" + version
+	if c.Retry, err = retryPolicy(cfg); err != nil {
 		return nil, err
 	}
-	return sgs, nil
+	return c, nil
 }
 
-// cloneGroupHierarchy recursively clones the entire group/subgroup hierarchy
-func cloneGroupHierarchy(group groupIdent, rootDir, proto string, isRoot bool) error {
-	// 1. Fetch data
-	subgroups, err := apiListSubgroups(group.ID)
+func apiListProjects(ctx context.Context, groupID int64) ([]glProject, error) {
+	api, err := newGitLabClient()
 	if err != nil {
-		return err
-	}
-	projects, err := apiListProjects(group.ID)
-	if err != nil {
-		return err
+		return nil, err
 	}
+	return api.ListGroupProjects(ctx, groupID)
+}
 
-	// 2. Write metadata
-	ashDir := filepath.Join(rootDir, ".ash")
-	if isRoot {
-		sgIdents := make([]subgroupIdent, 0, len(subgroups))
-		for _, sg := range subgroups {
-			sgIdents = append(sgIdents, subgroupIdent{ID: sg.ID, Path: sg.Path, Name: sg.Name})
-		}
-		writeGroupJSON(ashDir, rootGroupMeta{Group: group, Subgroups: sgIdents})
-	} else {
-		prjIdents := make([]projectIdent, 0, len(projects))
-		for _, p := range projects {
-			prjIdents = append(prjIdents, projectIdent{ID: p.ID, Path: p.Path, Name: p.Name})
-		}
-		writeSubgroupJSON(ashDir, subgroupMeta{Group: group, Projects: prjIdents})
-	}
-
-	// 3. Clone Projects (Batch)
-	if len(projects) > 0 {
-		fmt.Printf("Syncing %d projects in %s...\n", len(projects), rootDir)
-		for _, p := range projects {
-			url := p.HTTPURLToRepo
-			if proto == "ssh" {
-				url = p.SSHURLToRepo
-			}
-			dest := filepath.Join(rootDir, p.Name)
-			if !fileExists(dest) {
-				exec.Command("git", "clone", "--quiet", url, dest).Run()
-			}
-		}
+func apiListSubgroups(ctx context.Context, groupID int64) ([]glGroup, error) {
+	api, err := newGitLabClient()
+	if err != nil {
+		return nil, err
 	}
+	return api.ListSubgroups(ctx, groupID)
+}
 
-	// 4. Recurse Subgroups
-	for _, sg := range subgroups {
-		sgDir := filepath.Join(rootDir, sg.Name)
-		os.MkdirAll(sgDir, 0o755)
-		cloneGroupHierarchy(groupIdent{ID: sg.ID, Path: sg.Path}, sgDir, proto, false)
+// apiGetGroup fetches the details (name/path) of a single group.
+func apiGetGroup(ctx context.Context, groupID int64) (*glGroup, error) {
+	api, err := newGitLabClient()
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return api.GetGroup(ctx, groupID)
 }
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/warmdev17/ash/internal/gitlab"
+)
+
+// standInGitLab points newGitLabClient at a stand-in server running handler
+// for the duration of the test.
+func standInGitLab(t *testing.T, handler http.Handler) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	orig := newGitLabClient
+	t.Cleanup(func() { newGitLabClient = orig })
+	newGitLabClient = func() (gitlab.API, error) {
+		c, err := gitlab.NewClient(srv.URL, "tok")
+		if err != nil {
+			return nil, err
+		}
+		c.Retry = gitlab.RetryPolicy{}
+		return c, nil
+	}
+}
+
+func TestSiblingPathsFromGitLab(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/groups/10/subgroups", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 11, "name": "Session 1", "path": "session-1"}]`))
+	})
+	mux.HandleFunc("GET /api/v4/groups/10/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 12, "name": "Lab 1", "path": "Lab-1"}]`))
+	})
+	standInGitLab(t, mux)
+	ctx := t.Context()
+
+	found, sg, err := findSubgroupByPath(ctx, 10, "Session-1")
+	if err != nil {
+		t.Fatalf("findSubgroupByPath: %v", err)
+	}
+	if !found || sg.ID != 11 {
+		t.Errorf("findSubgroupByPath(session-1) = %v, %+v; want subgroup 11", found, sg)
+	}
+	if found, _, _ := findSubgroupByPath(ctx, 10, "session-2"); found {
+		t.Error("findSubgroupByPath(session-2) found a subgroup")
+	}
+
+	siblings, err := loadSiblingPaths(ctx, 10)
+	if err != nil {
+		t.Fatalf("loadSiblingPaths: %v", err)
+	}
+	for path, used := range map[string]string{"session-1": `subgroup "Session 1"`, "lab-1": `project "Lab 1"`} {
+		err := siblings.claim(path, "new project")
+		if err == nil || !strings.Contains(err.Error(), used) {
+			t.Errorf("claim(%q) = %v, want it to be used by %s", path, err, used)
+		}
+	}
+	if err := siblings.claim("lab-2", `project "Lab 2"`); err != nil {
+		t.Errorf("claim(lab-2) = %v", err)
+	}
+	if err := siblings.claim("LAB-2", "another project"); err == nil {
+		t.Error("claim(LAB-2) after claim(lab-2) succeeded")
+	}
+}
+
+func TestGitLabErrorsReachCommands(t *testing.T) {
+	standInGitLab(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "404 Group Not Found"}`))
+	}))
+
+	_, err := apiGetGroup(t.Context(), 99)
+	if !gitlab.IsNotFound(err) {
+		t.Fatalf("apiGetGroup = %v, want a 404", err)
+	}
+	if !strings.Contains(err.Error(), "Group Not Found") {
+		t.Errorf("error %q lacks GitLab's message", err)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// --- HIERARCHY CLONE ---
+// cloneGroupHierarchy works in three passes:
+//  1. walk the tree on GitLab, creating folders and group.json;
+//  2. clone every missing project through gitPool();
+//  3. write each level's metadata with the projects that are now on disk.
+
+// cloneLevel is one group of the tree with its direct subgroups and projects.
+type cloneLevel struct {
+	group     groupIdent
+	dir       string
+	isRoot    bool
+	subgroups []subgroupIdent
+	projects  []glProject
+	known     map[int64]string // folders already recorded in dir's metadata
+}
+
+// projectIdent is p as it will be recorded, in its known folder if any.
+func (lv *cloneLevel) projectIdent(p glProject) projectIdent {
+	ident := newProjectIdent(p)
+	if d, ok := lv.known[p.ID]; ok {
+		ident.Dir = d
+	}
+	return ident
+}
+
+// cloneReport is the outcome of cloneGroupHierarchy.
+type cloneReport struct {
+	Okay      []CloneResult
+	Failed    []CloneResult
+	Cancelled []CloneResult
+	Existing  int // projects whose folder was already there
+}
+
+// Print shows the summary; call it once the spinner has stopped.
+func (r *cloneReport) Print() {
+	printCloneSummary(r.Okay, r.Failed, r.Cancelled)
+	if r.Existing > 0 {
+		fmt.Printf("%d project(s) already present, left untouched.\n\n", r.Existing)
+	}
+}
+
+// Err is non-nil when a clone failed.
+func (r *cloneReport) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	total := len(r.Okay) + len(r.Failed) + len(r.Cancelled)
+	return fmt.Errorf("%d of %d clone(s) failed", len(r.Failed), total)
+}
+
+// cloneGroupHierarchy clones the group/subgroup tree below group into rootDir.
+// The returned report is valid (possibly partial) even when err is set: err
+// covers GitLab and metadata failures, report.Err() the clones themselves.
+// On cancellation partial clones are removed and left out of the metadata.
+func cloneGroupHierarchy(ctx context.Context, group groupIdent, rootDir, proto string, isRoot bool) (*cloneReport, error) {
+	report := &cloneReport{}
+
+	// 1. Walk the tree
+	var levels []*cloneLevel
+	if err := walkCloneLevels(ctx, group, rootDir, isRoot, &levels); err != nil {
+		return report, err
+	}
+
+	// 2. Clone in parallel
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	workers := gitPool()
+	cloned := make(map[string]bool) // dest -> on disk
+	for _, lv := range levels {
+		if len(lv.projects) > 0 {
+			fmt.Printf("Syncing %d projects in %s...\n", len(lv.projects), lv.dir)
+		}
+		for _, p := range lv.projects {
+			url := p.HTTPURLToRepo
+			if proto == "ssh" {
+				url = p.SSHURLToRepo
+			}
+			dest := filepath.Join(lv.dir, lv.projectIdent(p).Dir)
+			if fileExists(dest) {
+				report.Existing++
+				cloned[dest] = true
+				continue
+			}
+
+			wg.Add(1)
+			go func(url, dest, name string) {
+				defer wg.Done()
+				var res CloneResult
+				if workers.acquire(ctx) {
+					res = cloneOneRepo(ctx, url, dest, name)
+					workers.release()
+				} else {
+					res = CloneResult{Name: name, URL: url, Dest: dest, Err: ctx.Err()}
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case res.Err == nil:
+					report.Okay = append(report.Okay, res)
+					cloned[dest] = true
+				case ctx.Err() != nil:
+					os.RemoveAll(dest) // partial clone
+					report.Cancelled = append(report.Cancelled, res)
+				default:
+					report.Failed = append(report.Failed, res)
+				}
+			}(url, dest, p.Name)
+		}
+	}
+	wg.Wait()
+	for _, list := range [][]CloneResult{report.Okay, report.Failed, report.Cancelled} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Dest < list[j].Dest })
+	}
+
+	// 3. Level metadata lists what is on disk
+	var metaErrs []error
+	for _, lv := range levels {
+		prjIdents := make([]projectIdent, 0, len(lv.projects))
+		for _, p := range lv.projects {
+			ident := lv.projectIdent(p)
+			if cloned[filepath.Join(lv.dir, ident.Dir)] {
+				prjIdents = append(prjIdents, ident)
+			}
+		}
+		if lv.isRoot && len(prjIdents) == 0 {
+			continue // group.json was written by the walk
+		}
+		meta := levelMeta{Root: lv.isRoot, Group: lv.group, Subgroups: lv.subgroups, Projects: prjIdents}
+		if err := writeLevelMeta(lv.dir, meta); err != nil {
+			metaErrs = append(metaErrs, fmt.Errorf("write %s metadata: %w", lv.group.Name, err))
+		}
+	}
+	if len(metaErrs) > 0 {
+		return report, errors.Join(metaErrs...)
+	}
+	return report, ctx.Err()
+}
+
+// walkCloneLevels lists group and its subgroups depth-first, at any depth,
+// creating the folders and the root group.json (which anchors the workspace)
+// on the way.
+func walkCloneLevels(ctx context.Context, group groupIdent, dir string, isRoot bool, levels *[]*cloneLevel) error {
+	subgroups, err := apiListSubgroups(ctx, group.ID)
+	if err != nil {
+		return fmt.Errorf("list subgroups of %s: %w", group.Name, err)
+	}
+	projects, err := apiListProjects(ctx, group.ID)
+	if err != nil {
+		return fmt.Errorf("list projects of %s: %w", group.Name, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	known := knownFolders(dir)
+	sgIdents := make([]subgroupIdent, 0, len(subgroups))
+	for _, sg := range subgroups {
+		ident := newSubgroupIdent(sg)
+		if d, ok := known[sg.ID]; ok {
+			ident.Dir = d
+		}
+		sgIdents = append(sgIdents, ident)
+	}
+	if isRoot {
+		if err := writeGroupJSON(filepath.Join(dir, ".ash"), rootGroupMeta{Group: group, Subgroups: sgIdents}); err != nil {
+			return fmt.Errorf("write %s metadata: %w", group.Name, err)
+		}
+	}
+	*levels = append(*levels, &cloneLevel{group: group, dir: dir, isRoot: isRoot, subgroups: sgIdents, projects: projects, known: known})
+
+	for _, sg := range sgIdents {
+		if err := walkCloneLevels(ctx, sg.group(), filepath.Join(dir, sg.Dir), false, levels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
+)
+
+// discardLocalWork is bound to --discard-local-work on every command that
+// may remove local folders. It skips the local work check below.
+var discardLocalWork bool
+
+// promptMu serializes confirmation prompts coming from concurrent syncs.
+var promptMu sync.Mutex
+
+// localWork summarizes what would be lost if a git repository was deleted.
+type localWork struct {
+	Dir       string
+	Modified  int // modified, staged or deleted tracked files
+	Untracked int
+	Stashes   int
+	Unpushed  int // commits not reachable from any remote branch
+}
+
+func (w localWork) empty() bool {
+	return w.Modified == 0 && w.Untracked == 0 && w.Stashes == 0 && w.Unpushed == 0
+}
+
+func (w localWork) String() string {
+	var parts []string
+	add := func(n int, what string) {
+		if n == 0 {
+			return
+		}
+		if n > 1 {
+			what += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", n, what))
+	}
+	add(w.Modified, "modified file")
+	add(w.Untracked, "untracked file")
+	add(w.Unpushed, "unpushed commit")
+	add(w.Stashes, "stash entry")
+	return strings.Join(parts, ", ")
+}
+
+// inspectLocalWork reports the uncommitted, unpushed and stashed work of the
+// git repository at dir.
+func inspectLocalWork(ctx context.Context, dir string) (localWork, error) {
+	w := localWork{Dir: dir}
+
+	out, err := gitCmd(ctx, "-C", dir, "status", "--porcelain").Output()
+	if err != nil {
+		return w, fmt.Errorf("git status: %w", err)
+	}
+	for _, ln := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		switch {
+		case ln == "":
+		case strings.HasPrefix(ln, "??"):
+			w.Untracked++
+		default:
+			w.Modified++
+		}
+	}
+
+	out, err = gitCmd(ctx, "-C", dir, "stash", "list").Output()
+	if err != nil {
+		return w, fmt.Errorf("git stash list: %w", err)
+	}
+	if s := strings.TrimSpace(string(out)); s != "" {
+		w.Stashes = len(strings.Split(s, "\n"))
+	}
+
+	// A fresh repository has no HEAD yet, hence nothing unpushed.
+	if gitCmd(ctx, "-C", dir, "rev-parse", "--verify", "--quiet", "HEAD").Run() == nil {
+		out, err = gitCmd(ctx, "-C", dir, "rev-list", "--count", "--branches", "--not", "--remotes").Output()
+		if err != nil {
+			return w, fmt.Errorf("git rev-list: %w", err)
+		}
+		w.Unpushed, _ = strconv.Atoi(strings.TrimSpace(string(out)))
+	}
+	return w, nil
+}
+
+// scanLocalWork inspects every git repository at or below root and returns
+// those holding local work.
+func scanLocalWork(ctx context.Context, root string) ([]localWork, error) {
+	var found []localWork
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !fileExists(filepath.Join(p, ".git")) {
+			return nil
+		}
+		w, err := inspectLocalWork(ctx, p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		if !w.empty() {
+			found = append(found, w)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// describeLocalWork renders scan results relative to root, one repo per line.
+func describeLocalWork(root string, work []localWork) string {
+	var b strings.Builder
+	for _, w := range work {
+		rel, err := filepath.Rel(root, w.Dir)
+		if err != nil || rel == "." {
+			rel = filepath.Base(w.Dir)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", rel, w)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// checkLocalWork must pass before dir is removed. It inspects every repository
+// under dir and, if anything would be lost, asks for confirmation on a
+// terminal or refuses otherwise. --discard-local-work skips the check.
+func checkLocalWork(ctx context.Context, dir string) error {
+	if discardLocalWork || !fileExists(dir) {
+		return nil
+	}
+	work, err := scanLocalWork(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("cannot inspect %s for local work: %w", dir, err)
+	}
+	if len(work) == 0 {
+		return nil
+	}
+	desc := describeLocalWork(dir, work)
+
+	interactive := isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+	if !interactive {
+		return fmt.Errorf("refusing to delete %s, it has local work:\n%scommit and push it first, or use --discard-local-work", dir, indent(desc, "  "))
+	}
+
+	promptMu.Lock()
+	defer promptMu.Unlock()
+	confirmed := false
+	err = huh.NewConfirm().
+		Title(fmt.Sprintf("%s has local work that will be lost. Delete anyway?", dir)).
+		Description(desc).
+		Affirmative("Delete").
+		Negative("Keep").
+		Value(&confirmed).
+		Run()
+	if err != nil || !confirmed {
+		return fmt.Errorf("kept %s: local work not discarded", dir)
+	}
+	return nil
+}
+
+// localWorkSummary is the one-line form used in plans ("" when clean).
+func localWorkSummary(ctx context.Context, dir string) string {
+	work, err := scanLocalWork(ctx, dir)
+	if err != nil {
+		return "cannot inspect: " + err.Error()
+	}
+	if len(work) == 0 {
+		return ""
+	}
+	return strings.ReplaceAll(describeLocalWork(dir, work), "\n", "; ")
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/warmdev17/ash/internal/filelock"
+)
+
+// --- LOCKING ---
+// Every .ash directory and the config file have an advisory lock
+// (.ash/.lock, config.json.lock). Writers hold it while writing, and the
+// update* helpers hold it across a whole read-modify-write cycle, so two ash
+// processes (or goroutines) never lose each other's changes.
+
+const lockTimeout = 30 * time.Second
+
+func acquireLock(path, what string) (*filelock.Lock, error) {
+	l, err := filelock.Acquire(path, lockTimeout)
+	if errors.Is(err, filelock.ErrTimeout) {
+		return nil, fmt.Errorf("%s is in use by another ash process (waited %s; remove %s if no ash is running)", what, lockTimeout, path)
+	}
+	return l, err
+}
+
+// lockMetaDir locks the metadata in ashDir.
+func lockMetaDir(ashDir string) (*filelock.Lock, error) {
+	return acquireLock(filepath.Join(ashDir, ".lock"), ashDir)
+}
+
+// lockConfigFile locks the config file at cfgPath.
+func lockConfigFile(cfgPath string) (*filelock.Lock, error) {
+	return acquireLock(cfgPath+".lock", cfgPath)
+}
+
+// updateGroupMeta applies fn to the group.json in ashDir under its lock.
+// A missing file starts out empty.
+func updateGroupMeta(ashDir string, fn func(meta *rootGroupMeta) error) error {
+	l, err := lockMetaDir(ashDir)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	var meta rootGroupMeta
+	if err := readGroupMeta(filepath.Join(ashDir, "group.json"), &meta); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := fn(&meta); err != nil {
+		return err
+	}
+	meta.normalize(filepath.Dir(ashDir))
+	return writeJSON(filepath.Join(ashDir, "group.json"), meta)
+}
+
+// updateSubgroupMeta applies fn to the subgroup.json in ashDir under its lock.
+// A missing file starts out empty.
+func updateSubgroupMeta(ashDir string, fn func(meta *subgroupMeta) error) error {
+	l, err := lockMetaDir(ashDir)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	var meta subgroupMeta
+	if err := readSubgroupMeta(filepath.Join(ashDir, "subgroup.json"), &meta); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := fn(&meta); err != nil {
+		return err
+	}
+	meta.normalize(filepath.Dir(ashDir))
+	return writeJSON(filepath.Join(ashDir, "subgroup.json"), meta)
+}
+
+// updateConfig applies fn to the config under its lock and returns the config path.
+func updateConfig(fn func(cfg *AshConfig) error) (string, error) {
+	_, cfgPath, err := loadConfig()
+	if err != nil && cfgPath == "" {
+		return "", err
+	}
+	l, err := lockConfigFile(cfgPath)
+	if err != nil {
+		return cfgPath, err
+	}
+	defer l.Release()
+
+	cfg, _, err := loadConfig() // re-read under the lock
+	if err != nil {
+		return cfgPath, err
+	}
+	if err := fn(&cfg); err != nil {
+		return cfgPath, err
+	}
+	return cfgPath, writeJSONPerm(cfgPath, cfg, 0o600)
+}
+
+// updateLevelMeta applies fn to the metadata of the level folder dir
+// (group.json or subgroup.json, whichever it holds) under its lock.
+func updateLevelMeta(dir string, fn func(meta *levelMeta) error) error {
+	_, root, ok := levelMetaFile(dir)
+	if !ok {
+		return fmt.Errorf("%s: %w", filepath.Join(dir, ".ash"), os.ErrNotExist)
+	}
+	ashDir := filepath.Join(dir, ".ash")
+	if root {
+		return updateGroupMeta(ashDir, func(meta *rootGroupMeta) error {
+			lv := meta.level()
+			if err := fn(&lv); err != nil {
+				return err
+			}
+			*meta = lv.groupMeta()
+			return nil
+		})
+	}
+	return updateSubgroupMeta(ashDir, func(meta *subgroupMeta) error {
+		lv := meta.level()
+		if err := fn(&lv); err != nil {
+			return err
+		}
+		*meta = lv.subgroupMeta()
+		return nil
+	})
+}
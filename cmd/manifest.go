@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// --- MANIFEST ---
+// A manifest describes group hierarchies declaratively (see `ash apply`).
+// It is read from YAML or JSON; unknown keys are rejected so typos do not
+// silently fall back to defaults. Settings left empty are not managed: apply
+// neither sets nor changes them.
+
+type manifest struct {
+	Groups []manifestGroup `json:"groups" yaml:"groups"`
+}
+
+// manifestGroup is a top-level group or a subgroup at any depth.
+type manifestGroup struct {
+	Name        string            `json:"name" yaml:"name"`
+	Path        string            `json:"path,omitempty" yaml:"path,omitempty"` // default: slug of Name
+	Visibility  string            `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Subgroups   []manifestGroup   `json:"subgroups,omitempty" yaml:"subgroups,omitempty"`
+	Projects    []manifestProject `json:"projects,omitempty" yaml:"projects,omitempty"`
+}
+
+// manifestProject is one project, or with Prefix and Count the batch
+// Prefix1..PrefixN (like `ash project create -c N -p Prefix`).
+type manifestProject struct {
+	Name          string `json:"name,omitempty" yaml:"name,omitempty"`
+	Path          string `json:"path,omitempty" yaml:"path,omitempty"` // default: slug of Name
+	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
+	Visibility    string `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+	DefaultBranch string `json:"default_branch,omitempty" yaml:"default_branch,omitempty"`
+
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Count  int    `json:"count,omitempty" yaml:"count,omitempty"`
+}
+
+var validVisibility = map[string]bool{"public": true, "internal": true, "private": true}
+
+// loadManifest reads a manifest file ("-" for stdin). Files ending in .json
+// are parsed as JSON, anything else as YAML.
+func loadManifest(path string) (*manifest, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&m)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err = dec.Decode(&m); errors.Is(err, io.EOF) {
+			err = nil // empty file; reported by validate
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// validate checks the manifest and expands project batches in place.
+func (m *manifest) validate() error {
+	if len(m.Groups) == 0 {
+		return fmt.Errorf("no groups defined")
+	}
+	var errs []error
+	seen := make(map[string]bool)
+	for i := range m.Groups {
+		g := &m.Groups[i]
+		if p := g.slug(); p != "" && seen[p] {
+			errs = append(errs, fmt.Errorf("group %q: duplicate path %q", g.Name, p))
+		} else {
+			seen[p] = true
+		}
+		errs = append(errs, g.validate(g.Name, "group")...)
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks g and everything below it; kind is "group" for a
+// top-level group and "subgroup" otherwise (GitLab reserves different paths).
+func (g *manifestGroup) validate(where, kind string) []error {
+	var errs []error
+	if strings.TrimSpace(g.Name) == "" {
+		errs = append(errs, fmt.Errorf("%s: group without a name", where))
+	} else if err := checkPath(kind, g.slug()); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w (set \"path\")", where, err))
+	}
+	if g.Visibility != "" && !validVisibility[g.Visibility] {
+		errs = append(errs, fmt.Errorf("%s: invalid visibility %q (allowed: public, internal, private)", where, g.Visibility))
+	}
+
+	// Subgroups and projects share the group's namespace on GitLab.
+	seen := make(map[string]string)
+	claim := func(path, what string) {
+		if path == "" {
+			return
+		}
+		if other, ok := seen[path]; ok {
+			errs = append(errs, fmt.Errorf("%s: %s uses path %q, already taken by %s", where, what, path, other))
+			return
+		}
+		seen[path] = what
+	}
+
+	for i := range g.Subgroups {
+		sg := &g.Subgroups[i]
+		claim(sg.slug(), fmt.Sprintf("subgroup %q", sg.Name))
+		errs = append(errs, sg.validate(where+"/"+sg.Name, "subgroup")...)
+	}
+
+	var prjs []manifestProject
+	for _, p := range g.Projects {
+		batch, err := p.expand()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			continue
+		}
+		for _, bp := range batch {
+			if bp.Visibility != "" && !validVisibility[bp.Visibility] {
+				errs = append(errs, fmt.Errorf("%s/%s: invalid visibility %q (allowed: public, internal, private)", where, bp.Name, bp.Visibility))
+			}
+			if err := checkPath("project", bp.slug()); err != nil {
+				errs = append(errs, fmt.Errorf("%s/%s: %w (set \"path\")", where, bp.Name, err))
+			}
+			claim(bp.slug(), fmt.Sprintf("project %q", bp.Name))
+		}
+		prjs = append(prjs, batch...)
+	}
+	g.Projects = prjs
+	return errs
+}
+
+// expand turns a batch entry into its projects; a named entry is returned as is.
+func (p manifestProject) expand() ([]manifestProject, error) {
+	switch {
+	case p.Prefix == "" && p.Count == 0:
+		if strings.TrimSpace(p.Name) == "" {
+			return nil, fmt.Errorf("project without a name (set \"name\", or \"prefix\" and \"count\")")
+		}
+		return []manifestProject{p}, nil
+	case p.Name != "" || p.Path != "":
+		return nil, fmt.Errorf("project %q: \"name\"/\"path\" cannot be combined with \"prefix\"/\"count\"", p.Name+p.Prefix)
+	case p.Prefix == "" || p.Count <= 0:
+		return nil, fmt.Errorf("project batch needs both \"prefix\" and a positive \"count\"")
+	}
+	out := make([]manifestProject, 0, p.Count)
+	for i := 1; i <= p.Count; i++ {
+		bp := p
+		bp.Prefix, bp.Count = "", 0
+		bp.Name = fmt.Sprintf("%s%d", p.Prefix, i)
+		out = append(out, bp)
+	}
+	return out, nil
+}
+
+// matches reports whether an existing group with path is this entry; without
+// an explicit path, paths derived by older ash match too.
+func (g manifestGroup) matches(path string) bool {
+	if g.Path != "" {
+		return strings.EqualFold(path, g.Path)
+	}
+	return slugMatches(path, g.Name)
+}
+
+func (p manifestProject) matches(path string) bool {
+	if p.Path != "" {
+		return strings.EqualFold(path, p.Path)
+	}
+	return slugMatches(path, p.Name)
+}
+
+func (g manifestGroup) slug() string {
+	if g.Path != "" {
+		return g.Path
+	}
+	return slugify(g.Name)
+}
+
+func (p manifestProject) slug() string {
+	if p.Path != "" {
+		return p.Path
+	}
+	return slugify(p.Name)
+}
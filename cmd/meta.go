@@ -0,0 +1,13 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var metaCmd = &cobra.Command{
+	Use:   "meta",
+	Short: "Maintain the local .ash metadata files",
+	Long:  "Maintenance of the .ash/group.json and .ash/subgroup.json files that ash keeps in every group and subgroup folder.",
+}
+
+func init() {
+	rootCmd.AddCommand(metaCmd)
+}
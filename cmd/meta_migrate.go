@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var metaMigrateDryRun bool
+
+var metaMigrateCmd = &cobra.Command{
+	Use:   "migrate [dir]",
+	Short: "Rewrite all metadata files of a workspace in the current schema",
+	Long: `Upgrade every .ash/group.json and .ash/subgroup.json below the workspace root
+(the group folder containing the working directory, or [dir]) to the current
+schema_version. Older files are also upgraded in memory whenever ash reads them,
+so this is only needed to persist the new layout.
+
+With --dry-run, the files that would change are listed and nothing is written.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := ""
+		if len(args) > 0 {
+			root = args[0]
+		} else {
+			ws, err := currentWorkspace()
+			if err != nil {
+				return err
+			}
+			switch {
+			case ws.GroupRoot != "":
+				root = ws.GroupRoot
+			case ws.SubgroupRoot != "":
+				root = ws.SubgroupRoot
+			default:
+				root = ws.Dir
+			}
+		}
+
+		files, err := metaFiles(root)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no .ash metadata found below %s", root)
+		}
+
+		var results []TaskResult
+		failed := 0
+		for _, f := range files {
+			name := f
+			if rel, err := filepath.Rel(root, f); err == nil {
+				name = rel
+			}
+			res := migrateMetaFile(f, metaMigrateDryRun)
+			res.Name = name
+			if res.Status == "ERR" {
+				failed++
+			}
+			results = append(results, res)
+		}
+		PrintResults(results)
+
+		if failed > 0 {
+			return fmt.Errorf("%d metadata file(s) could not be migrated", failed)
+		}
+		return nil
+	},
+}
+
+// migrateMetaFile upgrades one metadata file in place (unless dryRun). A dry
+// run writes nothing, not even the lock file.
+func migrateMetaFile(path string, dryRun bool) TaskResult {
+	if !dryRun {
+		l, err := lockMetaDir(filepath.Dir(path))
+		if err != nil {
+			return TaskResult{Status: "ERR", Message: err.Error()}
+		}
+		defer l.Release()
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return TaskResult{Status: "ERR", Message: err.Error()}
+	}
+
+	var (
+		from int
+		v    any
+	)
+	if filepath.Base(path) == "group.json" {
+		var meta rootGroupMeta
+		from, err = loadGroupMeta(path, &meta)
+		v = meta
+	} else {
+		var meta subgroupMeta
+		from, err = loadSubgroupMeta(path, &meta)
+		v = meta
+	}
+	if err != nil {
+		return TaskResult{Status: "ERR", Message: err.Error()}
+	}
+
+	b, _ := json.MarshalIndent(v, "", "  ")
+	if bytes.Equal(bytes.TrimSpace(raw), b) {
+		return TaskResult{Status: "SKIP", Message: fmt.Sprintf("Up to date (v%d)", metaSchemaVersion)}
+	}
+
+	msg := fmt.Sprintf("v%d -> v%d", from, metaSchemaVersion)
+	if from == metaSchemaVersion {
+		msg = "Cleaned up entries"
+	}
+	if dryRun {
+		return TaskResult{Status: "NEW", Message: msg + " (dry-run)"}
+	}
+	if err := writeJSON(path, v); err != nil {
+		return TaskResult{Status: "ERR", Message: err.Error()}
+	}
+	return TaskResult{Status: "OK", Message: msg}
+}
+
+func init() {
+	metaCmd.AddCommand(metaMigrateCmd)
+	metaMigrateCmd.Flags().BoolVar(&metaMigrateDryRun, "dry-run", false, "List files that would change without writing them")
+}
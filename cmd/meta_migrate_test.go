@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateDryRunWritesNothing(t *testing.T) {
+	ashDir := filepath.Join(t.TempDir(), ".ash")
+	if err := os.Mkdir(ashDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(ashDir, "group.json")
+	legacy := []byte(`{"group": {"id": 1, "name": "Course", "path": "course"}, "subgroups": [{"id": 2, "name": "Session 1", "path": "session-1"}]}`)
+	if err := os.WriteFile(path, legacy, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := migrateMetaFile(path, true); res.Status != "NEW" {
+		t.Fatalf("dry run = %+v, want NEW", res)
+	}
+	entries, err := os.ReadDir(ashDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("dry run left %q in .ash, want only group.json", names)
+	}
+	if b, _ := os.ReadFile(path); string(b) != string(legacy) {
+		t.Errorf("dry run rewrote group.json:\n%s", b)
+	}
+
+	if res := migrateMetaFile(path, false); res.Status != "OK" {
+		t.Fatalf("migrate = %+v, want OK", res)
+	}
+	if res := migrateMetaFile(path, false); res.Status != "SKIP" {
+		t.Errorf("second migrate = %+v, want SKIP", res)
+	}
+}
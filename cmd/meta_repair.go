@@ -0,0 +1,499 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
+)
+
+var metaRepairDryRun bool
+
+var metaRepairCmd = &cobra.Command{
+	Use:   "repair [dir]",
+	Short: "Rebuild lost or corrupted metadata from local git remotes and GitLab",
+	Long: `Regenerate .ash/group.json and .ash/subgroup.json for the whole hierarchy
+containing the working directory (or [dir]).
+
+Each folder is matched to a GitLab subgroup or project by, in order:
+  1. the git remote of the repositories inside it,
+  2. the IDs in readable existing metadata,
+  3. its name (GitLab name or path).
+Folders that cannot be matched are reported and left untouched. A folder whose
+name differs from the GitLab name is recorded under its folder name, so the
+next 'sync' renames it. Unreadable metadata files are kept as <file>.bak.
+
+With --dry-run, the report is printed and nothing is written.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start := "."
+		if len(args) > 0 {
+			start = args[0]
+		}
+		ws, err := locateWorkspace(start)
+		if err != nil {
+			return err
+		}
+		api, err := newGitLabClient()
+		if err != nil {
+			return err
+		}
+
+		r := &metaRepair{ctx: cmd.Context(), api: api, dryRun: metaRepairDryRun}
+		switch {
+		case ws.GroupRoot != "":
+			r.root = ws.GroupRoot
+			err = r.repairGroupRoot(ws.GroupRoot)
+		case ws.SubgroupRoot != "":
+			r.root = ws.SubgroupRoot
+			err = r.repairSubgroupRoot(ws.SubgroupRoot)
+		default:
+			// No metadata at all: tell the layout apart by where the repositories are.
+			r.root = ws.Dir
+			switch guessFolderKind(r.ctx, ws.Dir) {
+			case "group":
+				err = r.repairGroupRoot(ws.Dir)
+			case "subgroup":
+				err = r.repairSubgroupRoot(ws.Dir)
+			default:
+				err = fmt.Errorf("nothing to repair: no .ash metadata or project repositories found in %s", ws.Dir)
+			}
+		}
+		PrintResults(r.results)
+		if err != nil {
+			return err
+		}
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+
+		if r.unresolved > 0 {
+			return fmt.Errorf("%d item(s) could not be reconciled (see [ERR] lines above)", r.unresolved)
+		}
+		if r.dryRun {
+			fmt.Println("[DRY-RUN] No changes made.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	metaCmd.AddCommand(metaRepairCmd)
+	metaRepairCmd.Flags().BoolVar(&metaRepairDryRun, "dry-run", false, "Report what would be rebuilt without writing anything")
+}
+
+// metaRepair collects the outcome of one repair run.
+type metaRepair struct {
+	ctx        context.Context
+	api        gitlab.API
+	root       string
+	dryRun     bool
+	results    []TaskResult
+	unresolved int
+}
+
+func (r *metaRepair) report(dir, status, format string, a ...any) {
+	name := dir
+	if rel, err := filepath.Rel(r.root, dir); err == nil {
+		name = rel
+	}
+	if status == "ERR" {
+		r.unresolved++
+	}
+	r.results = append(r.results, TaskResult{Name: name, Status: status, Message: fmt.Sprintf(format, a...)})
+}
+
+// reportMatch reports a matched folder when it needs attention: matched other
+// than by git remote, or named differently from GitLab. Plain matches are only
+// counted in the summary of the metadata file.
+func (r *metaRepair) reportMatch(dir, folder, kind, name, path string, id int64, how string) {
+	custom := !isDefaultFolder(folder, name, path)
+	if how == "git remote" && !custom {
+		return
+	}
+	msg := fmt.Sprintf("%s %s (ID: %d) matched by %s", kind, name, id, how)
+	if custom {
+		msg += "; recorded as its custom folder (kept when renamed on GitLab)"
+	}
+	r.report(dir, "OK", "%s", msg)
+}
+
+// --- GROUP ROOT ---
+
+func (r *metaRepair) repairGroupRoot(dir string) error {
+	ctx := r.ctx
+	metaPath := filepath.Join(dir, ".ash", "group.json")
+
+	// 1. Which GitLab group is this?
+	var old rootGroupMeta
+	oldErr := readGroupMeta(metaPath, &old)
+	var group *glGroup
+	var how string
+	if oldErr == nil && old.Group.ID != 0 {
+		if g, err := r.api.GetGroup(ctx, old.Group.ID); err == nil {
+			group, how = g, "metadata"
+		}
+	}
+	if group == nil {
+		// Project remotes two levels down: <group>/<subgroup>/<project>
+		for _, sub := range localSubdirs(dir) {
+			for _, rp := range folderRemotePaths(ctx, filepath.Join(dir, sub)) {
+				if g := r.groupFromRepoPath(rp, 2); g != nil {
+					group, how = g, "git remote"
+					break
+				}
+			}
+			if group != nil {
+				break
+			}
+		}
+	}
+	if group == nil {
+		cfg, _, _ := loadConfig()
+		base := filepath.Base(dir)
+		for _, g := range cfg.Groups {
+			if strings.EqualFold(g.Name, base) || strings.EqualFold(g.Path, base) {
+				if gg, err := r.api.GetGroup(ctx, g.ID); err == nil {
+					group, how = gg, "config"
+				}
+				break
+			}
+		}
+	}
+	if group == nil {
+		r.report(dir, "ERR", "Cannot identify the GitLab group (no readable metadata, git remotes or matching group in config)")
+		return nil
+	}
+	r.report(dir, "OK", "Group %s (ID: %d) identified by %s", group.Name, group.ID, how)
+
+	return r.repairLevel(dir, *group, true)
+}
+
+// --- LEVELS ---
+
+// repairLevel rebuilds the metadata of one level (the group root or a
+// subgroup at any depth) and descends into every matched subgroup. Folders
+// with a .git are matched to projects, others to subgroups first.
+func (r *metaRepair) repairLevel(dir string, g glGroup, root bool) error {
+	ctx := r.ctx
+	old, oldErr := readLevelMeta(dir)
+	oldIDs := make(map[string]int64)
+	for _, sg := range old.Subgroups {
+		oldIDs[sg.Dir] = sg.ID
+	}
+	for _, p := range old.Projects {
+		oldIDs[p.Dir] = p.ID
+	}
+
+	sgs, err := r.api.ListSubgroups(ctx, g.ID)
+	if err != nil {
+		return err
+	}
+	prjs, err := r.api.ListGroupProjects(ctx, g.ID)
+	if err != nil {
+		return err
+	}
+
+	// 1. Match local folders to remote subgroups and projects
+	folderOf := make(map[int64]string) // subgroup or project ID -> folder
+	for _, sub := range localSubdirs(dir) {
+		subDir := filepath.Join(dir, sub)
+		if !fileExists(filepath.Join(subDir, ".git")) {
+			if sg, how := matchSubgroupFolder(ctx, subDir, sub, sgs, oldIDs[sub], g.FullPath); sg != nil {
+				if other, taken := folderOf[sg.ID]; taken {
+					r.report(subDir, "ERR", "Subgroup %s is already matched by folder %q", sg.Name, other)
+					continue
+				}
+				folderOf[sg.ID] = sub
+				r.reportMatch(subDir, sub, "Subgroup", sg.Name, sg.Path, sg.ID, how)
+				continue
+			}
+		}
+		p, how, foreign := matchProjectFolder(ctx, subDir, sub, prjs, oldIDs[sub])
+		if p == nil {
+			if foreign != "" {
+				r.report(subDir, "ERR", "Git remote points to %s, which is not a project of %s", foreign, g.Name)
+			} else {
+				r.report(subDir, "ERR", "No matching subgroup or project in %s on GitLab", g.Name)
+			}
+			continue
+		}
+		if other, taken := folderOf[p.ID]; taken {
+			r.report(subDir, "ERR", "Project %s is already matched by folder %q", p.Name, other)
+			continue
+		}
+		folderOf[p.ID] = sub
+		r.reportMatch(subDir, sub, "Project", p.Name, p.Path, p.ID, how)
+	}
+
+	// Matching cut short by Ctrl-C saw no git remotes: write nothing from it
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// 2. Rebuild the metadata, recording the folders found on disk (a folder
+	// not named by the naming strategy is kept as a custom one)
+	syncCmd := "ash subgroup sync"
+	if root {
+		syncCmd = "ash group sync"
+	}
+	meta := levelMeta{Root: root, Group: groupIdent{ID: g.ID, Path: g.Path, Name: g.Name}, Template: old.Template}
+	matched := 0
+	for _, sg := range sgs {
+		ident := newSubgroupIdent(sg)
+		if folder, ok := folderOf[sg.ID]; ok {
+			ident.Dir = folder
+			matched++
+		} else {
+			r.report(filepath.Join(dir, ident.Dir), "NEW", "On GitLab only (fetch with '%s')", syncCmd)
+		}
+		meta.Subgroups = append(meta.Subgroups, ident)
+	}
+	for _, p := range prjs {
+		ident := newProjectIdent(p)
+		if folder, ok := folderOf[p.ID]; ok {
+			ident.Dir = folder
+			matched++
+		} else {
+			r.report(filepath.Join(dir, ident.Dir), "NEW", "On GitLab only (fetch with '%s')", syncCmd)
+		}
+		meta.Projects = append(meta.Projects, ident)
+	}
+	metaPath := filepath.Join(dir, ".ash", "subgroup.json")
+	if root {
+		metaPath = filepath.Join(dir, ".ash", "group.json")
+	}
+	r.writeMeta(metaPath, oldErr, func() error { return writeLevelMeta(dir, meta) },
+		"%d subgroups, %d projects, %d with a local folder", len(meta.Subgroups), len(meta.Projects), matched)
+
+	// 3. Descend into every matched subgroup
+	for _, sg := range sgs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if folder, ok := folderOf[sg.ID]; ok {
+			if err := r.repairLevel(filepath.Join(dir, folder), sg, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchSubgroupFolder finds the remote subgroup a local folder holds.
+func matchSubgroupFolder(ctx context.Context, dir, folder string, sgs []glGroup, oldID int64, parentPath string) (*glGroup, string) {
+	for _, rp := range folderRemotePaths(ctx, dir) {
+		ns := repoNamespace(rp)
+		for i := range sgs {
+			if repoPathMatches(ns, subgroupFullPath(sgs[i], parentPath)) {
+				return &sgs[i], "git remote"
+			}
+		}
+	}
+
+	var own subgroupMeta
+	if readSubgroupMeta(filepath.Join(dir, ".ash", "subgroup.json"), &own) == nil && own.Group.ID != 0 {
+		oldID = own.Group.ID
+	}
+	if oldID != 0 {
+		for i := range sgs {
+			if sgs[i].ID == oldID {
+				return &sgs[i], "metadata"
+			}
+		}
+	}
+
+	for i := range sgs {
+		if strings.EqualFold(folder, sgs[i].Name) || strings.EqualFold(folder, safeFolderName(sgs[i].Name)) || strings.EqualFold(folder, sgs[i].Path) {
+			return &sgs[i], "name"
+		}
+	}
+	return nil, ""
+}
+
+func subgroupFullPath(sg glGroup, parentPath string) string {
+	if sg.FullPath != "" {
+		return sg.FullPath
+	}
+	return parentPath + "/" + sg.Path
+}
+
+// --- SUBGROUP ---
+
+func (r *metaRepair) repairSubgroupRoot(dir string) error {
+	ctx := r.ctx
+
+	var old subgroupMeta
+	var sg *glGroup
+	if readSubgroupMeta(filepath.Join(dir, ".ash", "subgroup.json"), &old) == nil && old.Group.ID != 0 {
+		if g, err := r.api.GetGroup(ctx, old.Group.ID); err == nil {
+			sg = g
+		}
+	}
+	if sg == nil {
+		for _, rp := range folderRemotePaths(ctx, dir) {
+			if g := r.groupFromRepoPath(rp, 1); g != nil {
+				sg = g
+				break
+			}
+		}
+	}
+	if sg == nil {
+		r.report(dir, "ERR", "Cannot identify the GitLab subgroup (no readable metadata or git remotes)")
+		return nil
+	}
+	return r.repairLevel(dir, *sg, false)
+}
+
+// matchProjectFolder finds the remote project a local folder holds. When the
+// folder's git remote names a project outside prjs, that path is returned as foreign.
+func matchProjectFolder(ctx context.Context, dir, folder string, prjs []glProject, oldID int64) (p *glProject, how, foreign string) {
+	if remote := gitRemoteURL(ctx, dir); remote != "" {
+		rp := remoteRepoPath(remote)
+		for i := range prjs {
+			if repoPathMatches(rp, prjs[i].PathWithNamespace) {
+				return &prjs[i], "git remote", ""
+			}
+		}
+		return nil, "", rp
+	}
+	if oldID != 0 {
+		for i := range prjs {
+			if prjs[i].ID == oldID {
+				return &prjs[i], "metadata", ""
+			}
+		}
+	}
+	for i := range prjs {
+		if strings.EqualFold(folder, prjs[i].Name) || strings.EqualFold(folder, safeFolderName(prjs[i].Name)) || strings.EqualFold(folder, prjs[i].Path) {
+			return &prjs[i], "name (no git remote)", ""
+		}
+	}
+	return nil, "", ""
+}
+
+// writeMeta rewrites a metadata file, keeping an unreadable previous version as .bak.
+func (r *metaRepair) writeMeta(path string, readErr error, write func() error, format string, a ...any) {
+	dir := filepath.Dir(filepath.Dir(path))
+	name := filepath.Join(".ash", filepath.Base(path))
+	corrupt := readErr != nil && !errors.Is(readErr, os.ErrNotExist)
+	if r.dryRun {
+		r.report(dir, "NEW", "Would write %s (%s)", name, fmt.Sprintf(format, a...))
+		return
+	}
+	if corrupt {
+		if err := os.Rename(path, path+".bak"); err != nil {
+			r.report(dir, "ERR", "Cannot back up unreadable %s: %v", name, err)
+			return
+		}
+	}
+	if err := write(); err != nil {
+		r.report(dir, "ERR", "Write %s failed: %v", name, err)
+		return
+	}
+	msg := fmt.Sprintf("Wrote %s (%s)", name, fmt.Sprintf(format, a...))
+	if corrupt {
+		msg += "; unreadable original kept as " + filepath.Base(path) + ".bak"
+	}
+	r.report(dir, "OK", "%s", msg)
+}
+
+// --- GIT REMOTES ---
+
+// guessFolderKind tells a group folder (repositories two levels down) from a
+// subgroup folder (repositories directly below) when no metadata is left.
+func guessFolderKind(ctx context.Context, dir string) string {
+	for _, sub := range localSubdirs(dir) {
+		if fileExists(filepath.Join(dir, sub, ".git")) {
+			return "subgroup"
+		}
+	}
+	for _, sub := range localSubdirs(dir) {
+		if fileExists(filepath.Join(dir, sub, ".ash", "subgroup.json")) || len(folderRemotePaths(ctx, filepath.Join(dir, sub))) > 0 {
+			return "group"
+		}
+	}
+	return ""
+}
+
+// folderRemotePaths returns the repo paths of the git remotes of dir's child repositories.
+func folderRemotePaths(ctx context.Context, dir string) []string {
+	var paths []string
+	for _, sub := range localSubdirs(dir) {
+		if remote := gitRemoteURL(ctx, filepath.Join(dir, sub)); remote != "" {
+			if rp := remoteRepoPath(remote); rp != "" {
+				paths = append(paths, rp)
+			}
+		}
+	}
+	return paths
+}
+
+// groupFromRepoPath resolves the group `up` levels above the project named by
+// repoPath. Leading segments are dropped one by one to skip a relative URL root.
+func (r *metaRepair) groupFromRepoPath(repoPath string, up int) *glGroup {
+	segs := strings.Split(repoPath, "/")
+	if len(segs) <= up {
+		return nil
+	}
+	segs = segs[:len(segs)-up]
+	for i := range segs {
+		if g, err := r.api.GetGroupByPath(r.ctx, strings.Join(segs[i:], "/")); err == nil {
+			return g
+		}
+	}
+	return nil
+}
+
+// gitRemoteURL returns the origin URL of the repository at dir ("" if none).
+func gitRemoteURL(ctx context.Context, dir string) string {
+	if !fileExists(filepath.Join(dir, ".git")) {
+		return ""
+	}
+	out, err := gitCmd(ctx, "-C", dir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// remoteRepoPath extracts "group/subgroup/project" from a git remote URL:
+// https://host/a/b.git, git@host:a/b.git, ssh://git@host:22/a/b.git, file:///srv/a/b.git.
+func remoteRepoPath(remote string) string {
+	s := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(remote), "/"), ".git")
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+		j := strings.Index(s, "/")
+		if j < 0 {
+			return ""
+		}
+		s = s[j+1:]
+	} else if i := strings.Index(s, ":"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.Trim(s, "/")
+}
+
+// repoNamespace drops the project segment of a repo path.
+func repoNamespace(repoPath string) string {
+	if i := strings.LastIndex(repoPath, "/"); i >= 0 {
+		return repoPath[:i]
+	}
+	return ""
+}
+
+// repoPathMatches reports whether repoPath names fullPath, allowing a relative URL root prefix.
+func repoPathMatches(repoPath, fullPath string) bool {
+	if repoPath == "" || fullPath == "" {
+		return false
+	}
+	rp, fp := strings.ToLower(repoPath), strings.ToLower(fullPath)
+	return rp == fp || strings.HasSuffix(rp, "/"+fp)
+}
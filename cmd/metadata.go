@@ -0,0 +1,400 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- METADATA SCHEMA ---
+// .ash/group.json and .ash/subgroup.json carry a schema_version. On read, a
+// file is upgraded in memory by running every migration from its version up to
+// metaSchemaVersion; writers always produce the current layout, and
+// 'ash meta migrate' rewrites a whole tree.
+//
+// Versions:
+//   1  original layout, no schema_version; group.json lists subgroups under "subgroup"
+//   2  schema_version field; group.json lists subgroups under "subgroups";
+//      entries always carry both name and path
+//   3  any level may nest: group.json may list "projects" and subgroup.json
+//      "subgroups" (older ash would drop them on rewrite, hence the bump)
+//   4  any level may name a "template" for new projects (same reason)
+//   5  subgroup and project entries record their local folder as "dir"
+//      (filled from the folders on disk when missing)
+
+const metaSchemaVersion = 5
+
+// metaDoc is a metadata file decoded generically, so migrations can move keys around.
+type metaDoc = map[string]any
+
+// metaMigration upgrades a document by exactly one version.
+type metaMigration func(doc metaDoc) error
+
+// groupMetaMigrations[v] upgrades a group.json from version v to v+1.
+var groupMetaMigrations = map[int]metaMigration{
+	1: func(doc metaDoc) error {
+		if old, ok := doc["subgroup"]; ok {
+			if _, ok := doc["subgroups"]; !ok {
+				doc["subgroups"] = old
+			}
+			delete(doc, "subgroup")
+		}
+		return nil
+	},
+	2: func(doc metaDoc) error { return nil }, // "projects" added, optional
+	3: func(doc metaDoc) error { return nil }, // "template" added, optional
+	4: func(doc metaDoc) error { return nil }, // "dir" added, filled by normalize
+}
+
+// subgroupMetaMigrations[v] upgrades a subgroup.json from version v to v+1.
+var subgroupMetaMigrations = map[int]metaMigration{
+	1: func(doc metaDoc) error { return nil }, // layout unchanged; entries are cleaned up by normalize
+	2: func(doc metaDoc) error { return nil }, // "subgroups" added, optional
+	3: func(doc metaDoc) error { return nil }, // "template" added, optional
+	4: func(doc metaDoc) error { return nil }, // "dir" added, filled by normalize
+}
+
+// metaVersion returns the schema_version of doc; files without one are version 1.
+func metaVersion(doc metaDoc) (int, error) {
+	v, ok := doc["schema_version"]
+	if !ok {
+		return 1, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid schema_version %v", v)
+	}
+	ver, err := strconv.Atoi(n.String())
+	if err != nil || ver < 1 {
+		return 0, fmt.Errorf("invalid schema_version %v", v)
+	}
+	return ver, nil
+}
+
+// migrateMeta upgrades raw metadata with migrations and decodes the result into v.
+// It returns the version the document was stored at.
+func migrateMeta(raw []byte, migrations map[int]metaMigration, v any) (int, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber() // keep IDs exact
+	var doc metaDoc
+	if err := dec.Decode(&doc); err != nil {
+		return 0, fmt.Errorf("%w (run 'ash meta repair' to rebuild it)", err)
+	}
+	if doc == nil {
+		doc = metaDoc{}
+	}
+
+	from, err := metaVersion(doc)
+	if err != nil {
+		return 0, err
+	}
+	if from > metaSchemaVersion {
+		return from, fmt.Errorf("schema_version %d was written by a newer ash (this one supports up to %d); please upgrade ash", from, metaSchemaVersion)
+	}
+	for ver := from; ver < metaSchemaVersion; ver++ {
+		m, ok := migrations[ver]
+		if !ok {
+			return from, fmt.Errorf("no migration from schema_version %d", ver)
+		}
+		if err := m(doc); err != nil {
+			return from, fmt.Errorf("migrate from schema_version %d: %w", ver, err)
+		}
+	}
+	doc["schema_version"] = metaSchemaVersion
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return from, err
+	}
+	return from, json.Unmarshal(b, v)
+}
+
+// readGroupMeta reads .ash/group.json at path, upgrading older layouts.
+func readGroupMeta(path string, meta *rootGroupMeta) error {
+	_, err := loadGroupMeta(path, meta)
+	return err
+}
+
+// readSubgroupMeta reads .ash/subgroup.json at path, upgrading older layouts.
+func readSubgroupMeta(path string, meta *subgroupMeta) error {
+	_, err := loadSubgroupMeta(path, meta)
+	return err
+}
+
+func loadGroupMeta(path string, meta *rootGroupMeta) (int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	from, err := migrateMeta(raw, groupMetaMigrations, meta)
+	if err != nil {
+		return from, fmt.Errorf("%s: %w", path, err)
+	}
+	meta.normalize(filepath.Dir(filepath.Dir(path)))
+	return from, nil
+}
+
+func loadSubgroupMeta(path string, meta *subgroupMeta) (int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	from, err := migrateMeta(raw, subgroupMetaMigrations, meta)
+	if err != nil {
+		return from, fmt.Errorf("%s: %w", path, err)
+	}
+	meta.normalize(filepath.Dir(filepath.Dir(path)))
+	return from, nil
+}
+
+// --- NORMALIZATION ---
+// Applied on every read and write: stamps the current version, fills a missing
+// name, path or folder and drops entries that identify nothing. root is the
+// folder holding the .ash directory; since older ash named folders after
+// GitLab names, a missing name is taken from the matching local folder, else
+// from the path, and a missing folder is the one found on disk, else the name.
+
+func (m *rootGroupMeta) normalize(root string) {
+	m.SchemaVersion = metaSchemaVersion
+	normalizeGroup(&m.Group, root)
+	m.Subgroups = normalizeSubgroups(m.Subgroups, root)
+	m.Projects = normalizeProjects(m.Projects, root)
+	if len(m.Projects) == 0 {
+		m.Projects = nil // omitted from group.json
+	}
+}
+
+func (m *subgroupMeta) normalize(root string) {
+	m.SchemaVersion = metaSchemaVersion
+	normalizeGroup(&m.Group, root)
+	m.Projects = normalizeProjects(m.Projects, root)
+	m.Subgroups = normalizeSubgroups(m.Subgroups, root)
+	if len(m.Subgroups) == 0 {
+		m.Subgroups = nil // omitted from subgroup.json
+	}
+}
+
+func normalizeGroup(g *groupIdent, root string) {
+	if g.Name == "" {
+		g.Name = filepath.Base(root)
+	}
+	fillNamePath(&g.Name, &g.Path)
+}
+
+func normalizeSubgroups(in []subgroupIdent, root string) []subgroupIdent {
+	sgs := make([]subgroupIdent, 0, len(in))
+	for _, sg := range in {
+		if sg.ID == 0 && sg.Name == "" && sg.Path == "" {
+			continue
+		}
+		found := subgroupFolderName(root, sg.ID)
+		if sg.Name == "" {
+			sg.Name = found
+		}
+		fillNamePath(&sg.Name, &sg.Path)
+		if sg.Dir == "" {
+			sg.Dir = found
+		}
+		sg.Dir = normalizeDir(sg.Dir, sg.Name, root)
+		sgs = append(sgs, sg)
+	}
+	return sgs
+}
+
+func normalizeProjects(in []projectIdent, root string) []projectIdent {
+	prjs := make([]projectIdent, 0, len(in))
+	for _, p := range in {
+		if p.ID == 0 && p.Name == "" && p.Path == "" {
+			continue
+		}
+		fillNamePath(&p.Name, &p.Path)
+		p.Dir = normalizeDir(p.Dir, p.Name, root)
+		prjs = append(prjs, p)
+	}
+	return prjs
+}
+
+// normalizeDir keeps dir a single folder below root. A missing one is the
+// folder older ash used, the name itself, when it exists on disk.
+func normalizeDir(dir, name, root string) string {
+	if dir == "" && !strings.ContainsAny(name, `/\`) && isDir(filepath.Join(root, name)) {
+		return name
+	}
+	if dir == "" {
+		dir = name
+	}
+	if strings.ContainsAny(dir, `/\`) || dir == "." || dir == ".." {
+		return safeFolderName(dir)
+	}
+	return dir
+}
+
+func fillNamePath(name, path *string) {
+	if *name == "" {
+		*name = *path
+	}
+	if *path == "" {
+		*path = slugify(*name)
+	}
+}
+
+// subgroupFolderName finds the folder below root whose .ash/subgroup.json
+// belongs to subgroup id ("" if none).
+func subgroupFolderName(root string, id int64) string {
+	if id == 0 {
+		return ""
+	}
+	for _, dir := range localSubdirs(root) {
+		var head struct {
+			Group groupIdent `json:"group"`
+		}
+		if readJSON(filepath.Join(root, dir, ".ash", "subgroup.json"), &head) == nil && head.Group.ID == id {
+			return dir
+		}
+	}
+	return ""
+}
+
+// metaFiles lists the .ash/group.json and .ash/subgroup.json files below root,
+// skipping git internals and the trash.
+func metaFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" || (d.Name() == "trash" && filepath.Base(filepath.Dir(p)) == ".ash") {
+			return filepath.SkipDir
+		}
+		if d.Name() != ".ash" {
+			return nil
+		}
+		for _, name := range []string{"group.json", "subgroup.json"} {
+			if f := filepath.Join(p, name); fileExists(f) {
+				files = append(files, f)
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// --- LEVELS ---
+// A level is a folder holding .ash/group.json (the root) or .ash/subgroup.json.
+// Commands that only care about "the subgroups and projects here" go through
+// levelMeta so they work the same at every depth.
+
+// levelMetaFile returns the metadata file of the level folder dir and whether
+// it is the group root. ok is false when dir is not a level.
+func levelMetaFile(dir string) (path string, root, ok bool) {
+	if p := filepath.Join(dir, ".ash", "group.json"); fileExists(p) {
+		return p, true, true
+	}
+	if p := filepath.Join(dir, ".ash", "subgroup.json"); fileExists(p) {
+		return p, false, true
+	}
+	return "", false, false
+}
+
+// readLevelMeta reads the metadata of the level folder dir.
+func readLevelMeta(dir string) (levelMeta, error) {
+	path, root, ok := levelMetaFile(dir)
+	if !ok {
+		return levelMeta{}, fmt.Errorf("%s: %w", filepath.Join(dir, ".ash"), os.ErrNotExist)
+	}
+	if root {
+		var m rootGroupMeta
+		err := readGroupMeta(path, &m)
+		return m.level(), err
+	}
+	var m subgroupMeta
+	err := readSubgroupMeta(path, &m)
+	return m.level(), err
+}
+
+// writeLevelMeta writes meta to the file matching meta.Root in dir.
+func writeLevelMeta(dir string, meta levelMeta) error {
+	ashDir := filepath.Join(dir, ".ash")
+	if meta.Root {
+		return writeGroupJSON(ashDir, meta.groupMeta())
+	}
+	return writeSubgroupJSON(ashDir, meta.subgroupMeta())
+}
+
+func (m rootGroupMeta) level() levelMeta {
+	return levelMeta{Root: true, Group: m.Group, Subgroups: m.Subgroups, Projects: m.Projects, Template: m.Template}
+}
+
+func (m subgroupMeta) level() levelMeta {
+	return levelMeta{Group: m.Group, Subgroups: m.Subgroups, Projects: m.Projects, Template: m.Template}
+}
+
+func (m levelMeta) groupMeta() rootGroupMeta {
+	return rootGroupMeta{Group: m.Group, Subgroups: m.Subgroups, Projects: m.Projects, Template: m.Template}
+}
+
+func (m levelMeta) subgroupMeta() subgroupMeta {
+	return subgroupMeta{Group: m.Group, Subgroups: m.Subgroups, Projects: m.Projects, Template: m.Template}
+}
+
+// group is the identity of the subgroup itself, as stored in its subgroup.json.
+func (sg subgroupIdent) group() groupIdent {
+	return groupIdent{ID: sg.ID, Path: sg.Path, Name: sg.Name}
+}
+
+// kind names the level in messages.
+func (m levelMeta) kind() string {
+	if m.Root {
+		return "group"
+	}
+	return "subgroup"
+}
+
+// treeProject is a project listed in the metadata of some level below a root.
+type treeProject struct {
+	projectIdent
+	Level levelMeta // the level listing it
+	Dir   string    // its folder
+	Rel   string    // Dir relative to the root
+}
+
+// treeProjects lists the projects of the level folder root and of every
+// nested subgroup folder, each level sorted by folder.
+func treeProjects(root string) ([]treeProject, error) {
+	var out []treeProject
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		meta, err := readLevelMeta(dir)
+		if err != nil {
+			return err
+		}
+		prjs := append([]projectIdent(nil), meta.Projects...)
+		sort.Slice(prjs, func(i, j int) bool { return naturalLess(prjs[i].Dir, prjs[j].Dir) })
+		for _, p := range prjs {
+			tp := treeProject{projectIdent: p, Level: meta, Dir: filepath.Join(dir, p.Dir)}
+			tp.Rel, _ = filepath.Rel(root, tp.Dir)
+			out = append(out, tp)
+		}
+		sgs := append([]subgroupIdent(nil), meta.Subgroups...)
+		sort.Slice(sgs, func(i, j int) bool { return naturalLess(sgs[i].Dir, sgs[j].Dir) })
+		for _, sg := range sgs {
+			sgDir := filepath.Join(dir, sg.Dir)
+			if _, _, ok := levelMetaFile(sgDir); !ok {
+				continue // not scaffolded locally
+			}
+			if err := walk(sgDir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return out, walk(root)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"go.yaml.in/yaml/v3"
+)
+
+// --- OUTPUT FORMAT ---
+// Bound to the global --output/-o flag. List commands and TaskResult batches
+// emit stable JSON/YAML documents instead of tables when it is not "table".
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormat = outputTable
+
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid --output %q (allowed: table, json, yaml)", outputFormat)
+}
+
+// structuredOutput reports whether results must be written as JSON/YAML.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// infoOut is where progress and informational lines go: stdout, or stderr
+// when stdout carries JSON/YAML results, so they stay parseable.
+func infoOut() io.Writer {
+	if structuredOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// writeStructured writes v to stdout in the selected structured format.
+func writeStructured(v any) error {
+	switch outputFormat {
+	case outputYAML:
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+// setupColors turns ANSI colors off when stdout is not a terminal, when
+// NO_COLOR is set, or when a structured format is selected.
+func setupColors() {
+	tty := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	if tty && os.Getenv("NO_COLOR") == "" && !structuredOutput() {
+		return
+	}
+	Reset, Red, Green, Yellow, Blue, Cyan, Gray = "", "", "", "", "", "", ""
+	RED, GREEN, BLUE, YELLOW = "", "", "", ""
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// --- WORKER POOL ---
+// One pool per process bounds the number of repositories worked on at once
+// (clone, pull, submit), however the work is nested: a group sync running
+// several subgroup syncs shares the same slots. Size: --jobs, else the
+// "jobs" config key, else defaultJobs.
+
+const defaultJobs = 4
+
+var flagJobs int
+
+var (
+	poolOnce sync.Once
+	pool     *workerPool
+)
+
+type workerPool struct {
+	slots chan struct{}
+}
+
+// gitPool returns the process-wide pool, sized on first use.
+func gitPool() *workerPool {
+	poolOnce.Do(func() {
+		pool = &workerPool{slots: make(chan struct{}, jobCount())}
+	})
+	return pool
+}
+
+// jobCount resolves the pool size from the flag, the config and the default.
+func jobCount() int {
+	if rootCmd.PersistentFlags().Changed("jobs") {
+		return flagJobs
+	}
+	if cfg, _, err := loadConfig(); err == nil && cfg.Jobs > 0 {
+		return cfg.Jobs
+	}
+	return defaultJobs
+}
+
+func validateJobs() error {
+	if flagJobs < 1 {
+		return fmt.Errorf("invalid --jobs %d (must be at least 1)", flagJobs)
+	}
+	return nil
+}
+
+// acquire waits for a free slot. It returns false, without a slot, once ctx
+// is cancelled.
+func (p *workerPool) acquire(ctx context.Context) bool {
+	select {
+	case p.slots <- struct{}{}:
+		if ctx.Err() == nil {
+			return true
+		}
+		<-p.slots
+	case <-ctx.Done():
+	}
+	return false
+}
+
+// release frees a slot taken by acquire.
+func (p *workerPool) release() {
+	<-p.slots
+}
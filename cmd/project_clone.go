@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
@@ -15,18 +14,22 @@ var projectCloneCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
-		wd, _ := os.Getwd()
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
+		}
 
 		// Ensure we are in a subgroup for metadata update?
 		// User might want to clone just to check, but our tool relies on structure.
-		// Let's enforce structure for consistency.
-		subMetaPath := filepath.Join(wd, ".ash", "subgroup.json")
-		if !fileExists(subMetaPath) {
-			return fmt.Errorf("not in a subgroup folder (.ash/subgroup.json missing)")
+		// Let's enforce structure for consistency (any folder inside the level works).
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
+		}
+		meta, err := readLevelMeta(wd)
+		if err != nil {
+			return err
 		}
-
-		var meta subgroupMeta
-		readJSON(subMetaPath, &meta)
 
 		// 1. Find Remote Project
 		// Search in local meta first? If it's missing locally but exists remotely.
@@ -34,7 +37,7 @@ var projectCloneCmd = &cobra.Command{
 		found := false
 		var target glProject
 
-		prjs, err := apiListProjects(meta.Group.ID)
+		prjs, err := apiListProjects(cmd.Context(), meta.Group.ID)
 		if err != nil {
 			return err
 		}
@@ -52,8 +55,12 @@ var projectCloneCmd = &cobra.Command{
 
 		fmt.Printf("Cloning project %s...\n", target.Name)
 
-		// 2. Clone
-		dest := filepath.Join(wd, target.Name)
+		// 2. Clone (into the folder the metadata records, if any)
+		ident := newProjectIdent(target)
+		if d, ok := knownFolders(wd)[target.ID]; ok {
+			ident.Dir = d
+		}
+		dest := filepath.Join(wd, ident.Dir)
 		if fileExists(dest) {
 			return fmt.Errorf("folder %s already exists", dest)
 		}
@@ -71,7 +78,11 @@ var projectCloneCmd = &cobra.Command{
 		}
 
 		err = RunSpinner(fmt.Sprintf("Cloning project %s", target.Name), func() error {
-			if err := exec.Command("git", "clone", "--quiet", repoURL, dest).Run(); err != nil {
+			if err := gitCmd(cmd.Context(), "clone", "--quiet", repoURL, dest).Run(); err != nil {
+				if ctx := cmd.Context(); ctx.Err() != nil {
+					os.RemoveAll(dest) // partial clone
+					return ctx.Err()
+				}
 				return fmt.Errorf("git clone failed: %w", err)
 			}
 			return nil
@@ -81,16 +92,17 @@ var projectCloneCmd = &cobra.Command{
 		}
 
 		// 3. Update Meta
-		exists := false
-		for _, p := range meta.Projects {
-			if p.ID == target.ID {
-				exists = true
-				break
+		err = updateLevelMeta(wd, func(meta *levelMeta) error {
+			for _, p := range meta.Projects {
+				if p.ID == target.ID {
+					return nil
+				}
 			}
-		}
-		if !exists {
-			meta.Projects = append(meta.Projects, projectIdent{ID: target.ID, Name: target.Name, Path: target.Path})
-			writeSubgroupJSON(subMetaPath, meta)
+			meta.Projects = append(meta.Projects, ident)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("update %s metadata failed: %w", meta.kind(), err)
 		}
 
 		fmt.Println("[OK] Project cloned.")
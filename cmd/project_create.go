@@ -1,32 +1,50 @@
 package cmd
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 var (
 	createProjectCount  int
 	createProjectPrefix string
 	createProjectProto  string
+	createTemplate      string
+	createNoTemplate    bool
+	createFromProject   string
+	createImportURL     string
 )
 
 var projectCreateCmd = &cobra.Command{
 	Use:   "create [names...]",
 	Short: "Create projects (Interactive or Batch)",
-	Long: `Create one or more projects in the current subgroup.
+	Long: `Create one or more projects in the current subgroup (or in the group
+itself when run at the group root).
+
+New projects are seeded from the template selected for the current level
+('ash template set') or given with --template, and the starter files are
+pushed as the first commit. See 'ash template --help'.
+
+With --from-project or --import-url, GitLab creates the repository with
+content instead (from a custom project template, by forking a project that
+is not one, or by importing a git URL); ash waits for the import to finish,
+then clones it.
 
 Examples:
   ash project create BaiTap1 BaiTap2
-  ash project create -c 5 -p Lab`,
+  ash project create -c 5 -p Lab
+  ash project create Lab6 --template java-lab
+  ash project create Lab1 --from-project teachers/lab-template
+  ash project create Lab1 --import-url https://github.com/org/starter.git`,
 	SilenceUsage: true,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -39,19 +57,16 @@ Examples:
 		}
 
 		// 1. Env Check
-		wd, err := os.Getwd()
+		ws, err := currentWorkspace()
 		if err != nil {
 			return err
 		}
-		ashDir := filepath.Join(wd, ".ash")
-		subMetaPath := filepath.Join(ashDir, "subgroup.json")
-
-		if !fileExists(subMetaPath) {
-			return fmt.Errorf("not in a subgroup folder (.ash/subgroup.json missing)")
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
 		}
-
-		var meta subgroupMeta
-		if err := readJSON(subMetaPath, &meta); err != nil {
+		meta, err := readLevelMeta(wd)
+		if err != nil {
 			return fmt.Errorf("read metadata failed: %w", err)
 		}
 
@@ -68,84 +83,322 @@ Examples:
 			}
 		}
 
-		// 3. EXECUTE
+		// 3. Seed: a remote source, else a template (flag, else the
+		// level's; a git URL is cloned once)
+		ctx := cmd.Context()
+		var seed projectSeed
+		switch {
+		case createFromProject != "":
+			api, err := newGitLabClient()
+			if err != nil {
+				return err
+			}
+			src, err := api.GetProjectByPath(ctx, strings.Trim(createFromProject, "/"))
+			if gitlab.IsNotFound(err) {
+				return fmt.Errorf("project %q not found (use its full path, e.g. group/templates/lab)", createFromProject)
+			}
+			if err != nil {
+				return fmt.Errorf("look up project %s: %w", createFromProject, err)
+			}
+			if seed, err = fromProjectSeed(ctx, api, meta.Group.ID, src); err != nil {
+				return err
+			}
+		case createImportURL != "":
+			if !isGitURL(createImportURL) {
+				return fmt.Errorf("--import-url %q is not a git URL", createImportURL)
+			}
+			seed.ImportURL = createImportURL
+		case !createNoTemplate:
+			spec := createTemplate
+			if spec == "" {
+				spec, _ = levelTemplate(wd)
+			}
+			if spec != "" {
+				if seed.Template, err = loadTemplate(ctx, spec); err != nil {
+					return err
+				}
+				defer seed.Template.cleanup()
+			}
+		}
+
+		// 4. EXECUTE
 		var results []TaskResult
-		var mu sync.Mutex // Để append kết quả an toàn
 
 		title := fmt.Sprintf("Creating %d project(s)...", len(names))
 
+		var done []projectIdent
 		err = RunSpinner(title, func() error {
+			// Paths already taken here, so collisions fail before any POST
+			siblings, err := loadSiblingPaths(ctx, meta.Group.ID)
+			if err != nil {
+				return fmt.Errorf("list existing projects failed: %w", err)
+			}
+
 			for _, rawName := range names {
 				display := strings.TrimSpace(rawName)
 				if display == "" {
 					continue
 				}
 
-				res := createOneProject(wd, meta.Group.ID, display, createProjectProto)
+				var res TaskResult
+				path := slugify(display)
+				if ctx.Err() != nil {
+					res = cancelledResult(display)
+				} else if err := checkPath("project", path); err != nil {
+					res = TaskResult{Name: display, Status: "ERR", Message: err.Error()}
+				} else if err := siblings.claim(path, fmt.Sprintf("project %q", display)); err != nil {
+					res = TaskResult{Name: display, Status: "ERR", Message: err.Error()}
+				} else {
+					var p *glProject
+					res, p = createOneProject(ctx, wd, meta.Group, display, createProjectProto, seed)
+					if p != nil {
+						done = append(done, newProjectIdent(*p))
+					}
+				}
 
-				mu.Lock()
 				results = append(results, res)
-				mu.Unlock()
 			}
 
 			// Refresh Metadata Silent
-			refreshProjectMeta(ashDir, meta.Group.ID)
+			refreshProjectMeta(ctx, wd, meta.Group.ID, done)
 			return nil
 		})
 		if err != nil {
 			return err
 		}
 		PrintResults(results)
-		return nil
+		return ctx.Err()
 	},
 }
 
-func createOneProject(wd string, groupID int64, name string, proto string) TaskResult {
-	path := slugify(name)
+// projectSeed is where a new project's first content comes from: nothing, a
+// local template pushed after cloning, or a source GitLab copies on creation.
+type projectSeed struct {
+	Template    *projectTemplate
+	FromProject *glProject // custom project template, or the project to fork
+	ImportURL   string
 
-	createCmd := exec.Command("glab", "api", "/projects", "-X", "POST",
-		"-f", "name="+name,
-		"-f", "path="+path,
-		"-f", "namespace_id="+strconv.FormatInt(groupID, 10),
-		"-f", "visibility=public",
-	)
+	// How FromProject is copied: as a template of the group TemplatesOf
+	// (0: of the instance), or forked when it is no template at all.
+	TemplatesOf int64
+	Fork        bool
+}
 
-	out, err := createCmd.Output()
-	if err != nil {
-		return TaskResult{Name: name, Status: "ERR", Message: "GitLab create failed"}
+// fromProjectSeed picks how projects of group groupID are created from src.
+// GitLab only creates from projects of the template group set for the
+// instance or for groupID or one of its ancestors; anything else (and every
+// project on the free tier, which has no custom templates) is forked.
+func fromProjectSeed(ctx context.Context, api gitlab.API, groupID int64, src *glProject) (projectSeed, error) {
+	seed := projectSeed{FromProject: src}
+	ns := src.Namespace.ID
+	if ns == 0 || src.Namespace.Kind == "user" {
+		seed.Fork = true
+		return seed, nil
+	}
+	for id := groupID; id != 0; {
+		g, err := api.GetGroup(ctx, id)
+		if err != nil {
+			return seed, fmt.Errorf("look up group %d: %w", id, err)
+		}
+		if g.CustomProjectTemplatesGroupID == ns {
+			seed.TemplatesOf = ns
+			return seed, nil
+		}
+		id = g.ParentID
+	}
+	// Readable by administrators only; for anyone else the source cannot be
+	// told apart from a plain project and is forked.
+	if settings, err := api.GetApplicationSettings(ctx); err == nil && settings.CustomProjectTemplatesGroupID == ns {
+		return seed, nil
+	} else if ctx.Err() != nil {
+		return seed, ctx.Err()
+	}
+	seed.Fork = true
+	return seed, nil
+}
+
+// remote reports whether GitLab fills the repository itself.
+func (s projectSeed) remote() bool {
+	return s.FromProject != nil || s.ImportURL != ""
+}
+
+// source names the remote source in messages, without credentials.
+func (s projectSeed) source() string {
+	if s.FromProject != nil {
+		return s.FromProject.PathWithNamespace
+	}
+	if u, err := url.Parse(s.ImportURL); err == nil && u.User != nil {
+		return u.Redacted()
 	}
+	return s.ImportURL
+}
+
+// createOneProject creates the project on GitLab in group, clones it and,
+// with a template, seeds it. The project is returned once it is cloned.
+func createOneProject(ctx context.Context, wd string, group groupIdent, name string, proto string, seed projectSeed) (TaskResult, *glProject) {
+	path := slugify(name)
 
-	var pr glProject
-	if err := json.Unmarshal(out, &pr); err != nil {
-		return TaskResult{Name: name, Status: "ERR", Message: "Parse response failed"}
+	// A. Create on GitLab
+	api, err := newGitLabClient()
+	if err != nil {
+		return TaskResult{Name: name, Status: "ERR", Message: err.Error()}, nil
+	}
+	apiCtx, retries := gitlab.WithRetryStats(ctx)
+	var pr *glProject
+	if seed.Fork {
+		pr, err = api.ForkProject(apiCtx, seed.FromProject.ID, gitlab.ForkProjectOptions{
+			NamespaceID: group.ID,
+			Name:        name,
+			Path:        path,
+			Visibility:  "public",
+		})
+	} else {
+		opt := gitlab.CreateProjectOptions{
+			Name:        name,
+			Path:        path,
+			NamespaceID: group.ID,
+			Visibility:  "public",
+			ImportURL:   seed.ImportURL,
+		}
+		if seed.FromProject != nil {
+			opt.UseCustomTemplate = true
+			opt.TemplateProjectID = seed.FromProject.ID
+			opt.GroupWithProjectTemplatesID = seed.TemplatesOf
+		}
+		pr, err = api.CreateProject(apiCtx, opt)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return cancelledResult(name), nil
+		}
+		return TaskResult{Name: name, Status: "ERR", Message: withRetries(fmt.Sprintf("GitLab create failed: %v", err), retries)}, nil
+	}
+	if seed.remote() {
+		if err := waitForImport(ctx, api, pr.ID); err != nil {
+			if ctx.Err() != nil {
+				return TaskResult{Name: name, Status: "CANCELLED", Message: "Created on GitLab, import still running"}, nil
+			}
+			if errors.Is(err, errImportTimeout) {
+				return TaskResult{Name: name, Status: "ERR", Message: withRetries(fmt.Sprintf("Created but the import from %s did not finish within %s; check the project on GitLab", seed.source(), importTimeout), retries)}, nil
+			}
+			return TaskResult{Name: name, Status: "ERR", Message: withRetries(fmt.Sprintf("Created but import from %s failed: %v", seed.source(), err), retries)}, nil
+		}
 	}
 
 	// B. Clone
-	dest := filepath.Join(wd, name)
+	dest := filepath.Join(wd, defaultFolder(name, pr.Path))
 	repoURL := pr.HTTPURLToRepo
 	if proto == "ssh" {
 		repoURL = pr.SSHURLToRepo
 	}
 
-	if err := exec.Command("git", "clone", "--quiet", repoURL, dest).Run(); err != nil {
-		return TaskResult{Name: name, Status: "ERR", Message: "Created but Clone failed"}
+	if err := gitCmd(ctx, "clone", "--quiet", repoURL, dest).Run(); err != nil {
+		if ctx.Err() != nil {
+			os.RemoveAll(dest) // partial clone
+			return TaskResult{Name: name, Status: "CANCELLED", Message: "Created on GitLab, clone interrupted"}, nil
+		}
+		return TaskResult{Name: name, Status: "ERR", Message: withRetries("Created but Clone failed", retries)}, nil
 	}
 
-	return TaskResult{Name: name, Status: "OK", Message: "Ready"}
+	// C. Seed from the template
+	if seed.Fork {
+		return TaskResult{Name: name, Status: "OK", Message: withRetries("Ready (forked from "+seed.source()+")", retries)}, pr
+	}
+	if seed.remote() {
+		return TaskResult{Name: name, Status: "OK", Message: withRetries("Ready (from "+seed.source()+")", retries)}, pr
+	}
+	if tpl := seed.Template; tpl != nil {
+		vars := map[string]string{"name": name, "path": pr.Path, "group": group.Name}
+		if err := tpl.seed(ctx, dest, vars); err != nil {
+			if ctx.Err() != nil {
+				return TaskResult{Name: name, Status: "CANCELLED", Message: "Created and cloned, template interrupted"}, pr
+			}
+			return TaskResult{Name: name, Status: "ERR", Message: withRetries(fmt.Sprintf("Created and cloned, template failed: %v", err), retries)}, pr
+		}
+		return TaskResult{Name: name, Status: "OK", Message: withRetries("Ready (template "+tpl.Spec+")", retries)}, pr
+	}
+
+	return TaskResult{Name: name, Status: "OK", Message: withRetries("Ready", retries)}, pr
 }
 
-func refreshProjectMeta(ashDir string, groupID int64) {
-	prjs, _ := apiListProjects(groupID)
-	if len(prjs) > 0 {
-		var newMeta subgroupMeta
-		readJSON(filepath.Join(ashDir, "subgroup.json"), &newMeta)
+// importTimeout bounds how long waitForImport waits for GitLab.
+var importTimeout = 10 * time.Minute
 
+// errImportTimeout is returned when an import outlasts importTimeout.
+var errImportTimeout = errors.New("import still running")
+
+// waitForImport polls the project until GitLab has finished filling its
+// repository (imports run asynchronously), for at most importTimeout.
+func waitForImport(ctx context.Context, api gitlab.API, id int64) error {
+	ctx, cancel := context.WithTimeoutCause(ctx, importTimeout, errImportTimeout)
+	defer cancel()
+
+	delay := 500 * time.Millisecond
+	for {
+		p, err := api.GetProject(ctx, id)
+		if err != nil {
+			if context.Cause(ctx) == errImportTimeout {
+				return errImportTimeout
+			}
+			return err
+		}
+		switch p.ImportStatus {
+		case "", "none", "finished":
+			return nil
+		case "failed":
+			if p.ImportError != "" {
+				return fmt.Errorf("%s", p.ImportError)
+			}
+			return fmt.Errorf("import failed")
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+		if delay < 5*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+// refreshProjectMeta rewrites the project list of the level folder dir from
+// GitLab. After a cancellation only the projects in done (fully created) are
+// recorded.
+func refreshProjectMeta(ctx context.Context, dir string, groupID int64, done []projectIdent) {
+	if ctx.Err() != nil {
+		if len(done) == 0 {
+			return
+		}
+		updateLevelMeta(dir, func(meta *levelMeta) error {
+			known := make(map[int64]bool)
+			for _, p := range meta.Projects {
+				known[p.ID] = true
+			}
+			for _, p := range done {
+				if !known[p.ID] {
+					meta.Projects = append(meta.Projects, p)
+				}
+			}
+			return nil
+		})
+		return
+	}
+	prjs, _ := apiListProjects(ctx, groupID)
+	if len(prjs) > 0 {
+		known := knownFolders(dir)
 		idents := make([]projectIdent, 0, len(prjs))
 		for _, p := range prjs {
-			idents = append(idents, projectIdent{ID: p.ID, Path: p.Path, Name: p.Name})
+			ident := newProjectIdent(p)
+			if d, ok := known[p.ID]; ok {
+				ident.Dir = d // keep the folder already recorded
+			}
+			idents = append(idents, ident)
 		}
-		newMeta.Projects = idents
-		writeJSON(filepath.Join(ashDir, "subgroup.json"), newMeta)
+		updateLevelMeta(dir, func(meta *levelMeta) error {
+			meta.Projects = idents
+			return nil
+		})
 	}
 }
 
@@ -154,4 +407,9 @@ func init() {
 	projectCreateCmd.Flags().IntVarP(&createProjectCount, "count", "c", 0, "Number of projects")
 	projectCreateCmd.Flags().StringVarP(&createProjectPrefix, "prefix", "p", "", "Prefix for batch creation")
 	projectCreateCmd.Flags().StringVarP(&createProjectProto, "proto", "g", "https", "Protocol (https/ssh)")
+	projectCreateCmd.Flags().StringVar(&createTemplate, "template", "", "Seed new projects from a template (name, folder or git URL)")
+	projectCreateCmd.Flags().BoolVar(&createNoTemplate, "no-template", false, "Create empty projects even if the level selects a template")
+	projectCreateCmd.Flags().StringVar(&createFromProject, "from-project", "", "Create from a GitLab project: a custom project template, else a fork (full path)")
+	projectCreateCmd.Flags().StringVar(&createImportURL, "import-url", "", "Create by importing this git repository on GitLab")
+	projectCreateCmd.MarkFlagsMutuallyExclusive("template", "no-template", "from-project", "import-url")
 }
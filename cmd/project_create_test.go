@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFromProjectSeed(t *testing.T) {
+	// Course (10) > Session 1 (12); templates live in group 50.
+	tests := []struct {
+		name            string
+		groupTemplates  int64 // custom_project_templates_group_id of Course
+		adminTemplates  int64 // of the instance, 0: settings not readable
+		sourceNamespace string
+		wantTemplatesOf int64
+		wantFork        bool
+	}{
+		{"group template", 50, 0, `{"id": 50, "kind": "group"}`, 50, false},
+		{"instance template", 0, 50, `{"id": 50, "kind": "group"}`, 0, false},
+		{"template of another group", 60, 0, `{"id": 50, "kind": "group"}`, 0, true},
+		{"plain project", 0, 0, `{"id": 50, "kind": "group"}`, 0, true},
+		{"personal project", 0, 50, `{"id": 50, "kind": "user"}`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /api/v4/groups/12", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"id": 12, "name": "Session 1", "path": "session-1", "parent_id": 10}`))
+			})
+			mux.HandleFunc("GET /api/v4/groups/10", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"id": 10, "name": "Course", "path": "course", "custom_project_templates_group_id": %d}`, tt.groupTemplates)
+			})
+			mux.HandleFunc("GET /api/v4/application/settings", func(w http.ResponseWriter, r *http.Request) {
+				if tt.adminTemplates == 0 {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"message": "403 Forbidden"}`))
+					return
+				}
+				fmt.Fprintf(w, `{"custom_project_templates_group_id": %d}`, tt.adminTemplates)
+			})
+			mux.HandleFunc("GET /api/v4/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"id": 70, "name": "Lab", "path": "lab", "path_with_namespace": "teachers/lab", "namespace": %s}`, tt.sourceNamespace)
+			})
+			standInGitLab(t, mux)
+			api, err := newGitLabClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			src, err := api.GetProjectByPath(t.Context(), "teachers/lab")
+			if err != nil {
+				t.Fatalf("GetProjectByPath: %v", err)
+			}
+			seed, err := fromProjectSeed(t.Context(), api, 12, src)
+			if err != nil {
+				t.Fatalf("fromProjectSeed: %v", err)
+			}
+			if seed.FromProject != src || seed.TemplatesOf != tt.wantTemplatesOf || seed.Fork != tt.wantFork {
+				t.Errorf("seed = {TemplatesOf: %d, Fork: %v}, want {TemplatesOf: %d, Fork: %v}", seed.TemplatesOf, seed.Fork, tt.wantTemplatesOf, tt.wantFork)
+			}
+		})
+	}
+}
+
+func TestWaitForImportGivesUp(t *testing.T) {
+	standInGitLab(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 200, "import_status": "started"}`))
+	}))
+	api, err := newGitLabClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := importTimeout
+	importTimeout = 300 * time.Millisecond
+	t.Cleanup(func() { importTimeout = orig })
+
+	start := time.Now()
+	if err := waitForImport(t.Context(), api, 200); !errors.Is(err, errImportTimeout) {
+		t.Fatalf("waitForImport = %v, want errImportTimeout", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("waitForImport took %s", d)
+	}
+}
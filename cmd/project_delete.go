@@ -2,12 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
 
 	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 var (
@@ -18,72 +16,61 @@ var (
 var projectDeleteCmd = &cobra.Command{
 	Use:   "delete [name]",
 	Short: "Delete a project (on GitLab and local)",
-	Args:  cobra.MaximumNArgs(1), // 0 args if inside project, 1 arg if outside
+	Args:  cobra.MaximumNArgs(1), // 0 args if inside a project, 1 arg otherwise
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var name string
-		wd, _ := os.Getwd()
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
+		}
 
-		// 1. Determine Context & Name
-		inSubgroup := fileExists(filepath.Join(wd, ".ash", "subgroup.json"))
+		// 1. Determine Context & Name (works from any folder inside the level)
+		wd := ws.Level
+		if wd == "" {
+			return fmt.Errorf("must be run inside a group, subgroup or project folder")
+		}
 
 		if len(args) > 0 {
 			name = args[0]
+		} else if ws.inProject() {
+			name = ws.Project
 		} else {
-			// Infer from current dir?
-			// But wait, if we are INSIDE the project folder, typically there is no .ash file inside the project itself
-			// (unless we add one, but we don't).
-			// So we check if parent has subgroup.json?
-			parent := filepath.Dir(wd)
-			if fileExists(filepath.Join(parent, ".ash", "subgroup.json")) {
-				name = filepath.Base(wd)
-				// We need to jump up to parent to Execute delete (because we cant delete current dir easily)
-				if prjLocalForceDelete {
-					return fmt.Errorf("cannot delete local folder while inside it. Please cd .. and run 'ash project delete %s'", name)
-				}
-				// If just remote delete, it's fine.
-			} else {
-				return fmt.Errorf("missing project name and not inside a project folder")
-			}
+			return fmt.Errorf("missing project name and not inside a project folder")
 		}
 
-		// 2. Load Meta (from current dir or parent)
-		var metaPath string
-		if inSubgroup {
-			metaPath = filepath.Join(wd, ".ash", "subgroup.json")
-		} else {
-			// Try parent
-			parent := filepath.Dir(wd)
-			if fileExists(filepath.Join(parent, ".ash", "subgroup.json")) {
-				metaPath = filepath.Join(parent, ".ash", "subgroup.json")
-				wd = parent // Set WD to subgroup root for easier handling
-			} else {
-				return fmt.Errorf("must be run inside a subgroup or project folder")
-			}
-		}
-
-		var meta subgroupMeta
-		if err := readJSON(metaPath, &meta); err != nil {
+		meta, err := readLevelMeta(wd)
+		if err != nil {
 			return err
 		}
 
-		// Find ID
-		var targetID int64
-		var targetPath string
-		found := false
-		for _, p := range meta.Projects {
-			if p.Name == name {
-				targetID = p.ID
-				targetPath = p.Path
-				found = true
-				break
-			}
-		}
+		// Find ID (by name, or by folder when run inside it)
+		target, found := findProject(meta.Projects, name)
 		if !found {
 			return fmt.Errorf("project %q not found in metadata", name)
 		}
+		targetID, name := target.ID, target.Name
+
+		// We can't remove the folder we are standing in
+		if prjLocalForceDelete && ws.inProject() && ws.Project == target.Dir {
+			return fmt.Errorf("cannot delete local folder while inside it. Please cd %s and run 'ash project delete %s -l'", wd, name)
+		}
+
+		// Resolve the local folder up front so local work is checked before
+		// anything is deleted on GitLab.
+		localPath := filepath.Join(wd, target.Dir)
+		if prjLocalForceDelete {
+			if err := checkLocalWork(cmd.Context(), localPath); err != nil {
+				return err
+			}
+		}
 
 		fmt.Printf("Deleting project %s (ID: %d)...\n", name, targetID)
 
+		api, err := newGitLabClient()
+		if err != nil {
+			return err
+		}
+
 		// Check Empty? Projects are rarely "empty" in the sense of groups.
 		// GitLab API deletes repo.
 		// User said: "xoa khi trong".
@@ -93,21 +80,20 @@ var projectDeleteCmd = &cobra.Command{
 		// OR we trust the "Empty" definition of GitLab (fresh repo).
 		// But let's assume if it has Files, it's not empty.
 		if !prjForceDelete {
-			// We can list repository tree?
-			// glab api projects/:id/repository/tree
-			// If error or empty list -> Empty.
-			cmd := exec.Command("glab", "api", fmt.Sprintf("/projects/%d/repository/tree", targetID))
-			if out, _ := cmd.CombinedOutput(); len(out) > 5 { // json "[]" is 2 bytes
+			// An empty repository has no tree (GitLab answers 404).
+			nodes, err := api.ListRepositoryTree(cmd.Context(), targetID)
+			if err != nil && !gitlab.IsNotFound(err) {
+				return fmt.Errorf("check project content failed: %w", err)
+			}
+			if len(nodes) > 0 {
 				return fmt.Errorf("project is not empty. Use -f to force")
 			}
 		}
 
 		// API Delete
-		// API Delete
-		err := RunSpinner(fmt.Sprintf("Deleting project %s (ID: %d)", name, targetID), func() error {
-			glabCmd := exec.Command("glab", "api", "-X", "DELETE", "/projects/"+strconv.FormatInt(targetID, 10))
-			if out, err := glabCmd.CombinedOutput(); err != nil {
-				return fmt.Errorf("gitlab delete failed: %s (%w)", string(out), err)
+		err = RunSpinner(fmt.Sprintf("Deleting project %s (ID: %d)", name, targetID), func() error {
+			if err := api.DeleteProject(cmd.Context(), targetID); err != nil {
+				return fmt.Errorf("gitlab delete failed: %w", err)
 			}
 			return nil
 		})
@@ -116,23 +102,25 @@ var projectDeleteCmd = &cobra.Command{
 		}
 
 		// Update Meta
-		newPrjs := []projectIdent{}
-		for _, p := range meta.Projects {
-			if p.ID != targetID {
-				newPrjs = append(newPrjs, p)
+		err = updateLevelMeta(wd, func(meta *levelMeta) error {
+			newPrjs := []projectIdent{}
+			for _, p := range meta.Projects {
+				if p.ID != targetID {
+					newPrjs = append(newPrjs, p)
+				}
 			}
+			meta.Projects = newPrjs
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("update %s metadata failed: %w", meta.kind(), err)
 		}
-		meta.Projects = newPrjs
-		writeSubgroupJSON(metaPath, meta)
 
 		// Local Delete
-		if prjLocalForceDelete {
-			localPath := filepath.Join(wd, name)
-			if !fileExists(localPath) && fileExists(filepath.Join(wd, targetPath)) {
-				localPath = filepath.Join(wd, targetPath)
+		if prjLocalForceDelete && fileExists(localPath) {
+			if err := removeDir(localPath, "project", targetID, name); err != nil {
+				return fmt.Errorf("failed to remove local folder: %w", err)
 			}
-			os.RemoveAll(localPath)
-			fmt.Println("[DEL] Deleted local folder.")
 		}
 
 		return nil
@@ -143,4 +131,5 @@ func init() {
 	projectCmd.AddCommand(projectDeleteCmd)
 	projectDeleteCmd.Flags().BoolVarP(&prjForceDelete, "force", "f", false, "Force delete on GitLab")
 	projectDeleteCmd.Flags().BoolVarP(&prjLocalForceDelete, "local-force", "l", false, "Delete local folder")
+	projectDeleteCmd.Flags().BoolVar(&discardLocalWork, "discard-local-work", false, "With -l, delete the folder even if it holds uncommitted or unpushed work")
 }
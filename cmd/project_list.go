@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -11,23 +10,28 @@ import (
 
 var projectListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List projects in the current subgroup",
+	Short: "List projects in the current subgroup or group",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		wd, err := os.Getwd()
+		ws, err := currentWorkspace()
 		if err != nil {
 			return err
 		}
-		subMetaPath := filepath.Join(wd, ".ash", "subgroup.json")
-
-		if !fileExists(subMetaPath) {
-			return fmt.Errorf("not in a subgroup folder (.ash/subgroup.json missing)")
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
 		}
-
-		var meta subgroupMeta
-		if err := readJSON(subMetaPath, &meta); err != nil {
+		meta, err := readLevelMeta(wd)
+		if err != nil {
 			return err
 		}
 
+		if structuredOutput() {
+			if meta.Projects == nil {
+				meta.Projects = []projectIdent{}
+			}
+			return writeStructured(meta.Projects)
+		}
+
 		if len(meta.Projects) == 0 {
 			fmt.Println("No projects found in metadata.")
 			return nil
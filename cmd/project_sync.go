@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -15,21 +14,36 @@ var projectSyncCmd = &cobra.Command{
 	Use:   "sync [name...]",
 	Short: "Sync (git pull) project code",
 	Long: `Sync code for projects.
-If no arguments provided: Syncs ALL projects in the current subgroup.
-If arguments provided: Syncs only the specified projects.`,
+If no arguments provided: Syncs ALL projects in the current subgroup (or
+group root), or only the current project when run from inside one.
+If arguments provided: Syncs only the specified projects.
+Works from any folder inside the subgroup.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		wd, _ := os.Getwd()
-
-		// Check context
-		inSubgroup := fileExists(filepath.Join(wd, ".ash", "subgroup.json"))
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
+		}
 
+		var wd string        // Folder targets are relative to
 		var targets []string // Folders to sync
 
-		if len(args) > 0 {
-			targets = args
-		} else {
-			if inSubgroup {
-				// Scan all folders in subgroup that look like git repos
+		switch {
+		case ws.Level != "":
+			wd = ws.Level
+			if len(args) > 0 {
+				// Names are looked up in the metadata; anything else is a folder
+				meta, _ := readLevelMeta(wd)
+				for _, name := range args {
+					if p, ok := findProject(meta.Projects, name); ok {
+						name = p.Dir
+					}
+					targets = append(targets, name)
+				}
+			} else if ws.inProject() {
+				// Inside a project: sync just that one
+				targets = []string{ws.Project}
+			} else {
+				// Scan all folders in the level that look like git repos
 				entries, _ := os.ReadDir(wd)
 				for _, e := range entries {
 					if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
@@ -38,15 +52,18 @@ If arguments provided: Syncs only the specified projects.`,
 						}
 					}
 				}
-			} else {
-				// Maybe we are INSIDE a project?
-				if fileExists(filepath.Join(wd, ".git")) {
-					// Sync current
-					targets = []string{"."}
-				} else {
-					return fmt.Errorf("not in a subgroup or project folder")
-				}
 			}
+		case len(args) > 0:
+			wd = ws.Dir
+			targets = args
+		default:
+			// A standalone repo outside any ash workspace
+			top, err := gitCmd(cmd.Context(), "-C", ws.Dir, "rev-parse", "--show-toplevel").Output()
+			if err != nil {
+				return fmt.Errorf("not in a subgroup or project folder")
+			}
+			wd = filepath.Dir(strings.TrimSpace(string(top)))
+			targets = []string{filepath.Base(strings.TrimSpace(string(top)))}
 		}
 
 		if len(targets) == 0 {
@@ -57,20 +74,21 @@ If arguments provided: Syncs only the specified projects.`,
 		fmt.Printf("Syncing %d projects (git pull)...\n", len(targets))
 
 		// Concurrent Pull
+		ctx := cmd.Context()
 		var wg sync.WaitGroup
-		sem := make(chan struct{}, 5)
+		workers := gitPool()
 
 		for _, t := range targets {
 			wg.Add(1)
 			go func(dirName string) {
 				defer wg.Done()
-				sem <- struct{}{}
-				defer func() { <-sem }()
-
-				targetDir := dirName
-				if dirName != "." {
-					targetDir = filepath.Join(wd, dirName)
+				if !workers.acquire(ctx) {
+					fmt.Printf("%s[CANCELLED] %s not pulled%s\n", Yellow, dirName, Reset)
+					return
 				}
+				defer workers.release()
+
+				targetDir := filepath.Join(wd, dirName)
 
 				if !fileExists(filepath.Join(targetDir, ".git")) {
 					fmt.Printf("%s[SKIP] %s is not a git repo%s\n", Yellow, dirName, Reset)
@@ -79,9 +97,11 @@ If arguments provided: Syncs only the specified projects.`,
 
 				// Pull
 				// Remove --quiet to see if "Already up to date" or updates
-				out, err := exec.Command("git", "-C", targetDir, "pull").CombinedOutput()
+				out, err := gitCmd(ctx, "-C", targetDir, "pull").CombinedOutput()
 				output := string(out)
-				if err != nil {
+				if err != nil && ctx.Err() != nil {
+					fmt.Printf("%s[CANCELLED] %s pull interrupted%s\n", Yellow, dirName, Reset)
+				} else if err != nil {
 					fmt.Printf("%s[ERR] %s pull failed: %v\n%s%s\n", Red, dirName, err, output, Reset)
 				} else {
 					if strings.Contains(output, "Already up to date") {
@@ -96,7 +116,7 @@ If arguments provided: Syncs only the specified projects.`,
 			}(t)
 		}
 		wg.Wait()
-		return nil
+		return ctx.Err()
 	},
 }
 
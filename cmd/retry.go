@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/warmdev17/ash/internal/gitlab"
+)
+
+// --- API RETRIES ---
+// Transient GitLab failures (429, 502-504, ...) are retried with exponential
+// backoff. The policy comes from the config keys retries / retry_delay /
+// retry_max_delay, overridden by the global --retries / --retry-delay flags.
+
+var (
+	flagRetries    int
+	flagRetryDelay time.Duration
+)
+
+// retryPolicy merges the defaults, the config file and the global flags.
+func retryPolicy(cfg AshConfig) (gitlab.RetryPolicy, error) {
+	p := gitlab.DefaultRetryPolicy
+	if cfg.Retries != nil {
+		p.MaxRetries = *cfg.Retries
+	}
+	if cfg.RetryDelay != "" {
+		d, err := time.ParseDuration(cfg.RetryDelay)
+		if err != nil {
+			return p, fmt.Errorf("invalid retry_delay %q in config: %w", cfg.RetryDelay, err)
+		}
+		p.BaseDelay = d
+	}
+	if cfg.RetryMaxDelay != "" {
+		d, err := time.ParseDuration(cfg.RetryMaxDelay)
+		if err != nil {
+			return p, fmt.Errorf("invalid retry_max_delay %q in config: %w", cfg.RetryMaxDelay, err)
+		}
+		p.MaxDelay = d
+	}
+
+	flags := rootCmd.PersistentFlags()
+	if flags.Changed("retries") {
+		p.MaxRetries = flagRetries
+	}
+	if flags.Changed("retry-delay") {
+		p.BaseDelay = flagRetryDelay
+	}
+	if p.MaxRetries < 0 {
+		return p, fmt.Errorf("retries must be >= 0 (got %d)", p.MaxRetries)
+	}
+	return p, nil
+}
+
+// withRetries appends the retry count of an operation to a result message.
+func withRetries(msg string, stats *gitlab.RetryStats) string {
+	switch n := stats.Retries(); n {
+	case 0:
+		return msg
+	case 1:
+		return msg + " (after 1 retry)"
+	default:
+		return fmt.Sprintf("%s (after %d retries)", msg, n)
+	}
+}
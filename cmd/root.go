@@ -1,12 +1,16 @@
 package cmd
 
-
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 var version = "v2.1.0" // will be overridden by -ldflags
@@ -19,13 +23,42 @@ var rootCmd = &cobra.Command{
 	Short:   "Submit your homework quickly and efficiently",
 	Long:    `A CLI tool to automate GitLab-based homework submission and workflow management.`,
 	Version: version,
+
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(); err != nil {
+			return err
+		}
+		if err := validateJobs(); err != nil {
+			return err
+		}
+		if err := validateFolderNaming(); err != nil {
+			return err
+		}
+		setupColors()
+		return nil
+	},
 }
 
 func Execute() {
 	rootCmd.SetVersionTemplate("{{.Name}} {{.Version}}\n")
 
-	err := rootCmd.Execute()
+	// The root context is cancelled on Ctrl-C / SIGTERM. Commands pass it to
+	// every API call and git process so in-flight work stops cleanly.
+	// Once it fires, the default handlers are restored: a second Ctrl-C
+	// terminates immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	quietOnCancel(rootCmd)
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "%sInterrupted.%s\n", Yellow, Reset)
+			os.Exit(130)
+		}
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
@@ -36,6 +69,11 @@ func init() {
 
 	rootCmd.Flags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/ash/config.json)")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().IntVarP(&flagJobs, "jobs", "j", defaultJobs, "Max repositories worked on at once (clone, pull, submit, status, foreach)")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", gitlab.DefaultRetryPolicy.MaxRetries, "Retries for rate-limited or transiently failing GitLab API calls (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&flagRetryDelay, "retry-delay", gitlab.DefaultRetryPolicy.BaseDelay, "First backoff step between API retries (doubles each retry)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format for lists and results: table|json|yaml")
+	rootCmd.PersistentFlags().StringVar(&flagFolderNaming, "folder-naming", folderByName, "How new local folders are named: name|path")
 }
 
 // initConfig reads in config file and ENV variables if set.
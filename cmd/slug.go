@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// --- SLUGS ---
+// GitLab paths are derived from names. Names are mostly Vietnamese, so
+// diacritics are transliterated ("Bài Tập 1" -> "bai-tap-1") rather than
+// replaced by dashes as older ash did ("b-i-t-p-1"); legacySlugify keeps
+// the old form so paths created back then are still recognized.
+
+// maxPathLen is GitLab's limit for group and project paths.
+const maxPathLen = 255
+
+// foldRunes transliterates letters that do not decompose into a base
+// letter plus combining marks.
+var foldRunes = map[rune]string{
+	'đ': "d", 'Đ': "d",
+	'ø': "o", 'Ø': "o",
+	'ł': "l", 'Ł': "l",
+	'ß': "ss",
+	'æ': "ae", 'Æ': "ae",
+	'œ': "oe", 'Œ': "oe",
+}
+
+// slugify turns a name into a GitLab path: lowercase ASCII letters and
+// digits, with every other run of characters collapsed into one dash.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(strings.TrimSpace(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // combining mark of the previous letter: ầ -> a
+		}
+		if f, ok := foldRunes[r]; ok {
+			b.WriteString(f)
+			dash = false
+			continue
+		}
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := b.String()
+	if len(out) > maxPathLen {
+		out = out[:maxPathLen]
+	}
+	return strings.Trim(out, "-")
+}
+
+var legacySlugRe = regexp.MustCompile(`[^a-z0-9-]`)
+
+// legacySlugify is the path older ash derived from a name. It is only used
+// to match existing groups and projects, never to create new ones.
+func legacySlugify(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.ReplaceAll(s, " ", "-")
+	s = legacySlugRe.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-")
+}
+
+// slugMatches reports whether path is the one derived from name, by this
+// or an older ash.
+func slugMatches(path, name string) bool {
+	return strings.EqualFold(path, slugify(name)) || strings.EqualFold(path, legacySlugify(name))
+}
+
+// --- GITLAB PATH RULES ---
+
+var gitlabPathRe = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+
+// reservedTopLevel are routes GitLab refuses as top-level group paths.
+var reservedTopLevel = map[string]bool{
+	"admin": true, "api": true, "assets": true, "dashboard": true, "explore": true,
+	"files": true, "groups": true, "help": true, "import": true, "jwt": true,
+	"login": true, "oauth": true, "profile": true, "projects": true, "public": true,
+	"s": true, "search": true, "sitemap": true, "snippets": true, "unsubscribes": true,
+	"uploads": true, "users": true, "v2": true,
+}
+
+// reservedNested are routes GitLab refuses as subgroup and project paths.
+var reservedNested = map[string]bool{
+	"badges": true, "blame": true, "blob": true, "builds": true, "commits": true,
+	"create": true, "edit": true, "files": true, "new": true, "preview": true,
+	"raw": true, "refs": true, "tree": true, "update": true, "wikis": true,
+}
+
+// checkPath reports why GitLab would refuse path for a group (top-level),
+// subgroup or project, so ash can say so before calling the API.
+func checkPath(kind, path string) error {
+	switch {
+	case path == "":
+		return fmt.Errorf("cannot derive a %s path from the name; use letters or digits", kind)
+	case len(path) > maxPathLen:
+		return fmt.Errorf("%s path %q is longer than %d characters", kind, path, maxPathLen)
+	case !gitlabPathRe.MatchString(path) || strings.HasSuffix(path, "."):
+		return fmt.Errorf("%s path %q may only contain letters, digits, '_', '-' and '.', must not start with '-' or '.' nor end with '.'", kind, path)
+	case strings.HasSuffix(strings.ToLower(path), ".git") || strings.HasSuffix(strings.ToLower(path), ".atom"):
+		return fmt.Errorf("%s path %q must not end in .git or .atom", kind, path)
+	}
+	reserved := reservedNested
+	if kind == "group" {
+		reserved = reservedTopLevel
+	}
+	if reserved[strings.ToLower(path)] {
+		return fmt.Errorf("%s path %q is reserved by GitLab; choose another name", kind, path)
+	}
+	return nil
+}
+
+// --- COLLISIONS ---
+
+// siblingPaths records who uses each path inside one group: subgroups and
+// projects share that namespace on GitLab.
+type siblingPaths map[string]string // lowercased path -> `project "Lab1"`
+
+// loadSiblingPaths lists the paths already used under groupID.
+func loadSiblingPaths(ctx context.Context, groupID int64) (siblingPaths, error) {
+	sgs, err := apiListSubgroups(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	prjs, err := apiListProjects(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	s := make(siblingPaths)
+	for _, sg := range sgs {
+		s[strings.ToLower(sg.Path)] = fmt.Sprintf("subgroup %q", sg.Name)
+	}
+	for _, p := range prjs {
+		s[strings.ToLower(p.Path)] = fmt.Sprintf("project %q", p.Name)
+	}
+	return s, nil
+}
+
+// claim reserves path for what, or reports the sibling already using it
+// (names that differ only in accents or punctuation share a path).
+func (s siblingPaths) claim(path, what string) error {
+	key := strings.ToLower(path)
+	if other, ok := s[key]; ok {
+		return fmt.Errorf("path %q is already used by %s", path, other)
+	}
+	s[key] = what
+	return nil
+}
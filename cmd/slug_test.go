@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"Bài Tập 1", "bai-tap-1"},
+		{"Buổi học", "buoi-hoc"},
+		{"Đề thi cuối kỳ", "de-thi-cuoi-ky"},
+		{"Bưu điện", "buu-dien"},
+		{"Ơn giời", "on-gioi"},
+		{"Lab1", "lab1"},
+		{"CNTT2 - Spring 2025", "cntt2-spring-2025"},
+		{"a  --  b", "a-b"},
+		{"a_b.c", "a-b-c"},
+		{"  --Lab 1--  ", "lab-1"},
+		{"(Week 1)!", "week-1"},
+		{"!!!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.name); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSlugifyLength(t *testing.T) {
+	if got := slugify(strings.Repeat("a", 300)); len(got) != maxPathLen {
+		t.Errorf("300 letters gave a %d character path, want %d", len(got), maxPathLen)
+	}
+	// A dash cut at the limit is trimmed
+	name := strings.Repeat("a", maxPathLen-1) + " b"
+	if got := slugify(name); got != strings.Repeat("a", maxPathLen-1) {
+		t.Errorf("slugify(254 letters + \" b\") ends in %q", got[len(got)-3:])
+	}
+	if err := checkPath("project", strings.Repeat("a", maxPathLen)); err != nil {
+		t.Errorf("checkPath(255 letters) = %v", err)
+	}
+	if err := checkPath("project", strings.Repeat("a", maxPathLen+1)); err == nil {
+		t.Error("checkPath(256 letters) accepted it")
+	}
+}
+
+func TestSlugMatches(t *testing.T) {
+	tests := []struct {
+		path, name string
+		want       bool
+	}{
+		{"bai-tap-1", "Bài Tập 1", true},
+		{"b-i-t-p-1", "Bài Tập 1", true}, // made by an older ash
+		{"Bai-Tap-1", "Bài Tập 1", true},
+		{"bu-i-h-c", "Buổi học", true},
+		{"lab1", "Lab1", true},
+		{"lab-1", "Lab1", false},
+		{"bai-tap-2", "Bài Tập 1", false},
+	}
+	for _, tt := range tests {
+		if got := slugMatches(tt.path, tt.name); got != tt.want {
+			t.Errorf("slugMatches(%q, %q) = %v, want %v", tt.path, tt.name, got, tt.want)
+		}
+	}
+	if got := legacySlugify("Bài Tập 1"); got != "b-i-t-p-1" {
+		t.Errorf("legacySlugify(Bài Tập 1) = %q", got)
+	}
+}
+
+func TestCheckPath(t *testing.T) {
+	tests := []struct {
+		kind, path string
+		ok         bool
+	}{
+		{"group", "course", true},
+		{"project", "lab_1.v2", true},
+		{"group", "", false},
+		{"group", "admin", false},
+		{"group", "API", false},
+		{"group", "users", false},
+		{"subgroup", "admin", true}, // only reserved at the top level
+		{"subgroup", "tree", false},
+		{"project", "blob", false},
+		{"project", "new", false},
+		{"group", "new", true},
+		{"project", "-lab", false},
+		{"project", ".lab", false},
+		{"project", "lab.", false},
+		{"project", "lab.git", false},
+		{"project", "lab.ATOM", false},
+		{"project", "bài", false},
+	}
+	for _, tt := range tests {
+		if err := checkPath(tt.kind, tt.path); (err == nil) != tt.ok {
+			t.Errorf("checkPath(%s, %q) = %v, want ok %v", tt.kind, tt.path, err, tt.ok)
+		}
+	}
+}
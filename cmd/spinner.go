@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"time"
 
 	"github.com/mattn/go-isatty"
@@ -64,10 +64,10 @@ func RunSpinner(title string, fn func() error) error {
 }
 
 // cloneOneRepo runs `git clone` quietly and captures stderr (truncated).
-func cloneOneRepo(url, dest, name string) CloneResult {
+func cloneOneRepo(ctx context.Context, url, dest, name string) CloneResult {
 	start := time.Now()
 	var stderr bytes.Buffer
-	cmd := exec.Command("git", "clone", "--quiet", url, dest)
+	cmd := gitCmd(ctx, "clone", "--quiet", url, dest)
 	cmd.Stdout = nil
 	cmd.Stderr = &stderr
 
@@ -90,7 +90,7 @@ func cloneOneRepo(url, dest, name string) CloneResult {
 }
 
 // printCloneSummary prints a clean report after spinner stops.
-func printCloneSummary(okay, failed []CloneResult) {
+func printCloneSummary(okay, failed, cancelled []CloneResult) {
 	fmt.Println()
 	if len(okay) > 0 {
 		fmt.Printf("✔ Success (%d)\n", len(okay))
@@ -106,10 +106,16 @@ func printCloneSummary(okay, failed []CloneResult) {
 				fmt.Printf("     Error: %v\n", r.Err)
 			}
 			if r.Stderr != "" {
-				fmt.Printf("     Stderr: %s\n", indent(r.Stderr, "       "))
+				fmt.Printf("     Stderr:\n%s", indent(r.Stderr, "       "))
 			}
 		}
 	}
+	if len(cancelled) > 0 {
+		fmt.Printf("\n⏹ Cancelled (%d)\n", len(cancelled))
+		for _, r := range cancelled {
+			fmt.Printf("   ⏹ %s  →  %s\n", r.Name, r.Dest)
+		}
+	}
 	fmt.Println()
 }
 
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusFetch   bool
+	statusOffline bool
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of every project repository in the tree",
+	Long: `Show one line per project of the current group or subgroup and of every
+nested subgroup: branch, commits ahead of/behind origin, changed and untracked
+files, the last commit, whether it was ever submitted and whether the project
+still exists on GitLab.
+
+Ahead/behind compare with the last fetched state of origin; use --fetch to
+fetch first. A project counts as submitted once origin holds a commit by your
+git user.email.`,
+	Example: `  ash status
+  ash status --fetch
+  ash status -o json`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
+		}
+		root, err := ws.levelRoot()
+		if err != nil {
+			return err
+		}
+		ctx := cmd.Context()
+
+		var rows []*repoStatus
+		err = RunSpinner("Inspecting repositories...", func() error {
+			var err error
+			rows, err = collectStatus(ctx, root)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if structuredOutput() {
+			if rows == nil {
+				rows = []*repoStatus{}
+			}
+			if err := writeStructured(rows); err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
+		printStatus(rows)
+		return ctx.Err()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&statusFetch, "fetch", false, "Fetch origin before comparing (slower, needs the network)")
+	statusCmd.Flags().BoolVar(&statusOffline, "offline", false, "Do not ask GitLab whether the projects still exist")
+}
+
+// --- STATUS MODEL ---
+
+// repoStatus is the state of one project of the tree.
+type repoStatus struct {
+	Project string `json:"project" yaml:"project"`
+	ID      int64  `json:"id" yaml:"id"`
+	Dir     string `json:"dir" yaml:"dir"` // relative to the level status runs in
+	Cloned  bool   `json:"cloned" yaml:"cloned"`
+
+	Branch    string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Upstream  string `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+	Ahead     int    `json:"ahead" yaml:"ahead"`
+	Behind    int    `json:"behind" yaml:"behind"`
+	Changed   int    `json:"changed" yaml:"changed"` // modified, staged, deleted or conflicting
+	Untracked int    `json:"untracked" yaml:"untracked"`
+
+	LastCommitDate    string `json:"last_commit_date,omitempty" yaml:"last_commit_date,omitempty"` // RFC 3339
+	LastCommitMessage string `json:"last_commit_message,omitempty" yaml:"last_commit_message,omitempty"`
+
+	Submitted bool   `json:"submitted" yaml:"submitted"`
+	Remote    string `json:"remote" yaml:"remote"` // exists, gone or unknown
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+const (
+	remoteExists  = "exists"
+	remoteGone    = "gone"
+	remoteUnknown = "unknown"
+)
+
+// --- COLLECTION ---
+
+// collectStatus lists the projects of the level folder root and of every
+// nested subgroup folder, then inspects their repositories through gitPool().
+// Whether each project still exists is asked once per level.
+func collectStatus(ctx context.Context, root string) ([]*repoStatus, error) {
+	prjs, err := treeProjects(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*repoStatus
+	remote := make(map[int64]map[int64]bool) // group ID -> its project IDs
+	for _, p := range prjs {
+		ids, ok := remote[p.Level.Group.ID]
+		if !ok {
+			ids = remoteProjectIDs(ctx, p.Level.Group.ID)
+			remote[p.Level.Group.ID] = ids
+		}
+		r := &repoStatus{Project: p.Name, ID: p.ID, Dir: p.Rel, Remote: remoteUnknown}
+		r.Cloned = fileExists(filepath.Join(p.Dir, ".git"))
+		if ids != nil {
+			r.Remote = remoteGone
+			if ids[p.ID] {
+				r.Remote = remoteExists
+			}
+		}
+		rows = append(rows, r)
+	}
+
+	var wg sync.WaitGroup
+	workers := gitPool()
+	for i, r := range rows {
+		if !r.Cloned {
+			continue
+		}
+		wg.Add(1)
+		go func(r *repoStatus, dir string) {
+			defer wg.Done()
+			if !workers.acquire(ctx) {
+				r.Error = "cancelled"
+				return
+			}
+			defer workers.release()
+			if err := inspectRepoStatus(ctx, dir, r); err != nil {
+				r.Error = err.Error()
+			}
+		}(r, prjs[i].Dir)
+	}
+	wg.Wait()
+	return rows, nil
+}
+
+// remoteProjectIDs lists the projects of group on GitLab, nil when unknown
+// (--offline, or GitLab could not be asked).
+func remoteProjectIDs(ctx context.Context, groupID int64) map[int64]bool {
+	if statusOffline || groupID == 0 {
+		return nil
+	}
+	prjs, err := apiListProjects(ctx, groupID)
+	if err != nil {
+		return nil
+	}
+	ids := make(map[int64]bool, len(prjs))
+	for _, p := range prjs {
+		ids[p.ID] = true
+	}
+	return ids
+}
+
+// inspectRepoStatus fills r from the git repository at dir.
+func inspectRepoStatus(ctx context.Context, dir string, r *repoStatus) error {
+	if statusFetch {
+		if out, err := gitCmd(ctx, "-C", dir, "fetch", "--quiet", "origin").CombinedOutput(); err != nil {
+			return fmt.Errorf("git fetch: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	out, err := gitCmd(ctx, "-C", dir, "status", "--porcelain=v2", "--branch").Output()
+	if err != nil {
+		return fmt.Errorf("git status: %w", err)
+	}
+	for _, ln := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		switch {
+		case ln == "":
+		case strings.HasPrefix(ln, "# branch.head "):
+			r.Branch = strings.TrimPrefix(ln, "# branch.head ")
+		case strings.HasPrefix(ln, "# branch.upstream "):
+			r.Upstream = strings.TrimPrefix(ln, "# branch.upstream ")
+		case strings.HasPrefix(ln, "# branch.ab "):
+			// "# branch.ab +<ahead> -<behind>"
+			f := strings.Fields(ln)
+			if len(f) == 4 {
+				r.Ahead, _ = strconv.Atoi(strings.TrimPrefix(f[2], "+"))
+				r.Behind, _ = strconv.Atoi(strings.TrimPrefix(f[3], "-"))
+			}
+		case strings.HasPrefix(ln, "?"):
+			r.Untracked++
+		case strings.HasPrefix(ln, "#"), strings.HasPrefix(ln, "!"):
+		default:
+			r.Changed++
+		}
+	}
+
+	// A fresh repository has no commit yet.
+	if out, err := gitCmd(ctx, "-C", dir, "log", "-1", "--format=%cI%x00%s").Output(); err == nil {
+		if date, msg, ok := strings.Cut(strings.TrimSpace(string(out)), "\x00"); ok {
+			r.LastCommitDate, r.LastCommitMessage = date, msg
+		}
+	}
+
+	if r.Upstream != "" {
+		email, _ := gitCmd(ctx, "-C", dir, "config", "user.email").Output()
+		if e := strings.TrimSpace(string(email)); e != "" {
+			out, err := gitCmd(ctx, "-C", dir, "rev-list", "--count", "--author=<"+e+">", "--fixed-strings", r.Upstream).Output()
+			if err == nil {
+				n, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+				r.Submitted = n > 0
+			}
+		}
+	}
+	return nil
+}
+
+// --- TABLE OUTPUT ---
+
+func printStatus(rows []*repoStatus) {
+	if len(rows) == 0 {
+		fmt.Println("No projects found in metadata.")
+		return
+	}
+
+	var changed, unpushed, notSubmitted, gone, missing int
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tBRANCH\tSYNC\tCHANGED\tUNTRACKED\tLAST COMMIT\tSUBMITTED\tGITLAB")
+	for _, r := range rows {
+		if r.Remote == remoteGone {
+			gone++
+		}
+		if !r.Cloned {
+			missing++
+			fmt.Fprintf(w, "%s\t-\tnot cloned\t-\t-\t-\t-\t%s\n", r.Dir, r.Remote)
+			continue
+		}
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t-\terror: %s\t-\t-\t-\t-\t%s\n", r.Dir, r.Error, r.Remote)
+			continue
+		}
+		if r.Changed+r.Untracked > 0 {
+			changed++
+		}
+		if r.Ahead > 0 {
+			unpushed++
+		}
+		submitted := "no"
+		if r.Submitted {
+			submitted = "yes"
+		} else {
+			notSubmitted++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+			r.Dir, r.Branch, r.syncState(), r.Changed, r.Untracked, r.lastCommit(), submitted, r.Remote)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d project(s): %d with local changes, %d with unpushed commits, %d not submitted", len(rows), changed, unpushed, notSubmitted)
+	if missing > 0 {
+		fmt.Printf(", %d not cloned", missing)
+	}
+	if gone > 0 {
+		fmt.Printf(", %s%d gone on GitLab%s", Red, gone, Reset)
+	}
+	fmt.Println()
+}
+
+// syncState is "up to date", "↑2 ↓1" or "no upstream".
+func (r *repoStatus) syncState() string {
+	switch {
+	case r.Upstream == "":
+		return "no upstream"
+	case r.Ahead == 0 && r.Behind == 0:
+		return "up to date"
+	}
+	var parts []string
+	if r.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", r.Ahead))
+	}
+	if r.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", r.Behind))
+	}
+	return strings.Join(parts, " ")
+}
+
+// lastCommit is "2006-01-02 message", the message cut to fit a table.
+func (r *repoStatus) lastCommit() string {
+	if r.LastCommitDate == "" {
+		return "-"
+	}
+	date := r.LastCommitDate
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		date = t.Local().Format("2006-01-02")
+	}
+	msg := r.LastCommitMessage
+	if rs := []rune(msg); len(rs) > 40 {
+		msg = string(rs[:39]) + "…"
+	}
+	return date + " " + msg
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
@@ -10,19 +9,20 @@ import (
 
 var subgroupCloneCmd = &cobra.Command{
 	Use:   "clone [name]",
-	Short: "Clone a specific subgroup into the current group",
+	Short: "Clone a specific subgroup into the current group or subgroup",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
-		wd, _ := os.Getwd()
-		groupMetaPath := filepath.Join(wd, ".ash", "group.json")
-
-		if !fileExists(groupMetaPath) {
-			return fmt.Errorf("not in a group root (.ash/group.json missing)")
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
 		}
-
-		var meta rootGroupMeta
-		if err := readJSON(groupMetaPath, &meta); err != nil {
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
+		}
+		meta, err := readLevelMeta(wd)
+		if err != nil {
 			return err
 		}
 
@@ -30,13 +30,16 @@ var subgroupCloneCmd = &cobra.Command{
 		// Or search in current meta?
 		// Usually if we want to clone, maybe we manually deleted it or it wasn't there?
 		// Let's search API.
-		found, sg, err := findSubgroupByPath(meta.Group.ID, slugify(name))
+		found, sg, err := findSubgroupByPath(cmd.Context(), meta.Group.ID, slugify(name))
+		if err == nil && !found {
+			found, sg, err = findSubgroupByPath(cmd.Context(), meta.Group.ID, legacySlugify(name))
+		}
 		if err != nil {
 			return err
 		}
 		if !found {
 			// Try fuzzy match or exact name match
-			all, _ := apiListSubgroups(meta.Group.ID)
+			all, _ := apiListSubgroups(cmd.Context(), meta.Group.ID)
 			for _, s := range all {
 				if s.Name == name {
 					sg = s
@@ -52,9 +55,12 @@ var subgroupCloneCmd = &cobra.Command{
 
 		fmt.Printf("Cloning subgroup: %s (ID: %d)\n", sg.Name, sg.ID)
 
-		// Create Folder
-		targetDir := filepath.Join(wd, sg.Name)
-		os.MkdirAll(targetDir, 0o755)
+		// Create Folder (the one the metadata records, if any)
+		ident := newSubgroupIdent(sg)
+		if d, ok := knownFolders(wd)[sg.ID]; ok {
+			ident.Dir = d
+		}
+		targetDir := filepath.Join(wd, ident.Dir)
 
 		// Determine Protocol
 		proto := "https"
@@ -64,29 +70,34 @@ var subgroupCloneCmd = &cobra.Command{
 		}
 
 		// Recurse Clone
+		var report *cloneReport
 		err = RunSpinner(fmt.Sprintf("Cloning subgroup %s", sg.Name), func() error {
-			if err := cloneGroupHierarchy(groupIdent{ID: sg.ID, Path: sg.Path, Name: sg.Name}, targetDir, proto, false); err != nil {
-				return err
-			}
-			return nil
+			var err error
+			report, err = cloneGroupHierarchy(cmd.Context(), groupIdent{ID: sg.ID, Path: sg.Path, Name: sg.Name}, targetDir, proto, false)
+			return err
 		})
+		report.Print()
 		if err != nil {
 			return err
 		}
 
 		// Update Parent Meta if missing
-		exists := false
-		for _, s := range meta.Subgroups {
-			if s.ID == sg.ID {
-				exists = true
-				break
+		err = updateLevelMeta(wd, func(meta *levelMeta) error {
+			for _, s := range meta.Subgroups {
+				if s.ID == sg.ID {
+					return nil
+				}
 			}
-		}
-		if !exists {
-			meta.Subgroups = append(meta.Subgroups, subgroupIdent{ID: sg.ID, Path: sg.Path, Name: sg.Name})
-			writeGroupJSON(filepath.Join(wd, ".ash"), meta)
+			meta.Subgroups = append(meta.Subgroups, ident)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("update %s metadata failed: %w", meta.kind(), err)
 		}
 
+		if err := report.Err(); err != nil {
+			return err
+		}
 		fmt.Println("[OK] Subgroup cloned.")
 		return nil
 	},
@@ -1,104 +1,109 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/warmdev17/ash/internal/gitlab"
 )
 
 var (
-	subgroupCreateDir  string // --dir: local folder name (optional, default = subgroup display name)
+	subgroupCreateDir  string // --dir: custom local folder name (optional, default = see folders.go)
 	subgroupVisibility string // --visibility: public|internal|private (default public)
 )
 
 var subgroupCreateCmd = &cobra.Command{
 	Use:   "create [name]",
-	Short: "Create a subgroup under the current group and scaffold a local folder",
+	Short: "Create a subgroup under the current group or subgroup and scaffold a local folder",
 	Example: `  cd MyGroup
   ash subgroup create "Session 1"
   ash subgroup create "Session 1" --dir S1
-  ash subgroup create "Secret Lab" --visibility private`,
+  ash subgroup create "Secret Lab" --visibility private
+  cd "Session 1" && ash subgroup create "Week 1"   # nested subgroup`,
 	Args:          cobra.ExactArgs(1),
 	SilenceUsage:  true,
 	SilenceErrors: true,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
+		if d := strings.TrimSpace(subgroupCreateDir); d != "" && (d != safeFolderName(d) || d == "." || d == "..") {
+			return fmt.Errorf("--dir %q must be a single folder name (no / \\ : * ? \" < > |)", d)
+		}
 
-		// 1) Must be inside a group root (has .ash/group.json, NOT .ash/subgroup.json)
-		wd, err := os.Getwd()
+		// 1) Must be inside a level (nearest .ash/group.json or .ash/subgroup.json
+		// above the working directory); the new subgroup goes under it
+		ws, err := currentWorkspace()
 		if err != nil {
 			return fmt.Errorf("getwd failed: %w", err)
 		}
-		ashDir := filepath.Join(wd, ".ash")
-		groupMetaPath := filepath.Join(ashDir, "group.json")
-		subMetaPath := filepath.Join(ashDir, "subgroup.json")
-
-		if !fileExists(groupMetaPath) {
-			return fmt.Errorf("not in a group root: %s not found", groupMetaPath)
-		}
-		if fileExists(subMetaPath) {
-			return fmt.Errorf("this looks like a subgroup folder (found %s); run from the group root", subMetaPath)
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
 		}
 
-		// 2) Read current group meta (need parent group ID)
-		var meta rootGroupMeta
-		if err := readJSON(groupMetaPath, &meta); err != nil {
-			return fmt.Errorf("parse group.json failed: %w", err)
+		// 2) Read current level meta (need parent group ID)
+		meta, err := readLevelMeta(wd)
+		if err != nil {
+			return fmt.Errorf("read metadata failed: %w", err)
 		}
 		if meta.Group.ID == 0 {
-			return fmt.Errorf("invalid group.json: missing group.id")
+			return fmt.Errorf("invalid %s metadata: missing group.id", meta.kind())
 		}
 
 		// 3) Prepare subgroup slug/path
 		path := slugify(name)
+		if err := checkPath("subgroup", path); err != nil {
+			return err
+		}
 
 		// 3a) Preflight: check if subgroup already exists under the parent (avoid 409)
-		existed, existedSG, err := findSubgroupByPath(meta.Group.ID, path)
+		existed, existedSG, err := findSubgroupByPath(cmd.Context(), meta.Group.ID, path)
+		if err == nil && !existed && legacySlugify(name) != path {
+			// created by an older ash, which derived paths differently
+			existed, existedSG, err = findSubgroupByPath(cmd.Context(), meta.Group.ID, legacySlugify(name))
+		}
 		if err != nil {
 			return fmt.Errorf("check existing subgroup failed: %w", err)
 		}
-		if existed {
+		if existed && strings.EqualFold(existedSG.Name, name) {
 			fmt.Printf("Subgroup already exists on GitLab: id=%d name=%q path=%q\n", existedSG.ID, existedSG.Name, existedSG.Path)
 			return scaffoldAndLinkSubgroup(wd, &meta, existedSG.ID, existedSG.Name, existedSG.Path)
 		}
 
-		// 4) Create subgroup on GitLab via glab (default visibility = public)
+		// 3b) A different subgroup or a project may already use the path
+		siblings, err := loadSiblingPaths(cmd.Context(), meta.Group.ID)
+		if err != nil {
+			return fmt.Errorf("check existing paths failed: %w", err)
+		}
+		if err := siblings.claim(path, fmt.Sprintf("subgroup %q", name)); err != nil {
+			return fmt.Errorf("cannot create subgroup %q: %w; choose another name", name, err)
+		}
+
+		// 4) Create subgroup on GitLab (default visibility = public)
 		if subgroupVisibility == "" {
 			subgroupVisibility = "public"
 		}
 
-		var created struct {
-			ID   int64  `json:"id"`
-			Name string `json:"name"`
-			Path string `json:"path"`
+		api, err := newGitLabClient()
+		if err != nil {
+			return err
 		}
 
+		var created *glGroup
 		err = RunSpinner(fmt.Sprintf("Creating subgroup %s", name), func() error {
-			argsPost := []string{
-				"api", "-X", "POST", "/groups",
-				"-f", "name=" + name,
-				"-f", "path=" + path,
-				"-f", fmt.Sprintf("parent_id=%d", meta.Group.ID),
-				"-f", "visibility=" + subgroupVisibility, // default public
-			}
-			glabCmd := exec.Command("glab", argsPost...)
-			out, err := glabCmd.CombinedOutput()
+			sg, err := api.CreateGroup(cmd.Context(), gitlab.CreateGroupOptions{
+				Name:       name,
+				Path:       path,
+				ParentID:   meta.Group.ID,
+				Visibility: subgroupVisibility, // default public
+			})
 			if err != nil {
-				return fmt.Errorf("glab create subgroup failed: %v\n%s", err, string(out))
-			}
-
-			if err := json.Unmarshal(out, &created); err != nil {
-				return fmt.Errorf("failed to parse glab response: %w", err)
-			}
-			if created.ID == 0 || created.Path == "" || created.Name == "" {
-				return fmt.Errorf("unexpected glab response: missing id/name/path")
+				return fmt.Errorf("create subgroup failed: %w", err)
 			}
+			created = sg
 			return nil
 		})
 		if err != nil {
@@ -107,26 +112,25 @@ var subgroupCreateCmd = &cobra.Command{
 
 		fmt.Printf("Created subgroup: id=%d name=%q path=%q\n", created.ID, created.Name, created.Path)
 
-		// 5) Local scaffold + update parent group.json
+		// 5) Local scaffold + update parent metadata
 		return scaffoldAndLinkSubgroup(wd, &meta, created.ID, created.Name, created.Path)
 	},
 }
 
 func init() {
 	subgroupCmd.AddCommand(subgroupCreateCmd)
-	subgroupCreateCmd.Flags().StringVar(&subgroupCreateDir, "dir", "", "Custom local directory name (optional)")
+	subgroupCreateCmd.Flags().StringVar(&subgroupCreateDir, "dir", "", "Custom local folder name, kept when the subgroup is renamed (optional)")
 	subgroupCreateCmd.Flags().StringVar(&subgroupVisibility, "visibility", "public", "Subgroup visibility: public|internal|private (default: public)")
 }
 
 // ---------- helpers (local to subgroup create) ----------
 
-func scaffoldAndLinkSubgroup(wd string, meta *rootGroupMeta, sgID int64, sgName, sgPath string) error {
-	ashDir := filepath.Join(wd, ".ash")
-
-	// Scaffold folder: <dirOrName>/.ash/subgroup.json (empty projects)
+func scaffoldAndLinkSubgroup(wd string, meta *levelMeta, sgID int64, sgName, sgPath string) error {
+	// Scaffold folder: <dir>/.ash/subgroup.json (empty projects); --dir is
+	// recorded as the subgroup's custom folder
 	dirName := strings.TrimSpace(subgroupCreateDir)
 	if dirName == "" {
-		dirName = sgName
+		dirName = defaultFolder(sgName, sgPath)
 	}
 	subDir := filepath.Join(wd, dirName)
 	if err := os.MkdirAll(subDir, 0o755); err != nil {
@@ -134,7 +138,7 @@ func scaffoldAndLinkSubgroup(wd string, meta *rootGroupMeta, sgID int64, sgName,
 	}
 
 	sgMeta := subgroupMeta{
-		Group:    groupIdent{ID: sgID, Path: sgPath},
+		Group:    groupIdent{ID: sgID, Path: sgPath, Name: sgName},
 		Projects: []projectIdent{},
 	}
 	if err := writeSubgroupJSON(filepath.Join(subDir, ".ash"), sgMeta); err != nil {
@@ -143,27 +147,36 @@ func scaffoldAndLinkSubgroup(wd string, meta *rootGroupMeta, sgID int64, sgName,
 	fmt.Printf("Scaffolded: %s\n", subDir)
 	fmt.Printf("Wrote: %s\n", filepath.Join(subDir, ".ash", "subgroup.json"))
 
-	// Update parent's .ash/group.json (dedupe by Name, case-insensitive)
+	// Update parent's metadata (dedupe by ID or Name, case-insensitive)
+	metaFile := "group.json"
+	if !meta.Root {
+		metaFile = "subgroup.json"
+	}
 	lower := strings.ToLower(sgName)
 	exists := false
-	for _, s := range meta.Subgroups {
-		if strings.ToLower(s.Name) == lower {
-			exists = true
-			break
+	err := updateLevelMeta(wd, func(fresh *levelMeta) error {
+		for _, s := range fresh.Subgroups {
+			if s.ID == sgID || strings.ToLower(s.Name) == lower {
+				exists = true
+				return nil
+			}
 		}
-	}
-	if !exists {
-		meta.Subgroups = append(meta.Subgroups, subgroupIdent{
+		fresh.Subgroups = append(fresh.Subgroups, subgroupIdent{
 			ID:   sgID,
 			Path: sgPath,
 			Name: sgName,
+			Dir:  dirName,
 		})
-		if err := writeGroupJSON(ashDir, *meta); err != nil {
-			return fmt.Errorf("update group.json failed: %w", err)
-		}
-		fmt.Println("Updated parent .ash/group.json")
+		*meta = *fresh
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("update %s failed: %w", metaFile, err)
+	}
+	if !exists {
+		fmt.Printf("Updated parent .ash/%s\n", metaFile)
 	} else {
-		fmt.Println("Subgroup already listed in parent group.json; no change")
+		fmt.Printf("Subgroup already listed in parent %s; no change\n", metaFile)
 	}
 
 	fmt.Println("Done.")
@@ -2,10 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -21,60 +19,76 @@ var subgroupDeleteCmd = &cobra.Command{
 	Long: `Delete a subgroup.
 Usage:
   ash subgroup delete Session1
-  (Must be run from the Group root directory)
+  (Run from the group or subgroup that contains it)
 
 Behavior:
   - Deletes from GitLab.
-  - Removes from parent group.json.
-  - Optional: -l to delete local folder.`,
+  - Removes from the parent's metadata (group.json or subgroup.json).
+  - Optional: -l to move the local folder to the trash (refused if it holds local git work,
+    unless confirmed or --discard-local-work is given).`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
-		wd, _ := os.Getwd()
-		groupMetaPath := filepath.Join(wd, ".ash", "group.json")
-
-		if !fileExists(groupMetaPath) {
-			return fmt.Errorf("not in a group root")
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
 		}
-
-		var meta rootGroupMeta
-		if err := readJSON(groupMetaPath, &meta); err != nil {
+		wd, err := ws.levelRoot()
+		if err != nil {
 			return err
 		}
-
-		// Find ID
-		var targetID int64
-		var targetPath string
-		found := false
-		for _, sg := range meta.Subgroups {
-			if sg.Name == name {
-				targetID = sg.ID
-				targetPath = sg.Path
-				found = true
-				break
-			}
+		meta, err := readLevelMeta(wd)
+		if err != nil {
+			return err
 		}
 
+		// Find ID (by name, or by folder)
+		target, found := findSubgroup(meta.Subgroups, name)
 		if !found {
 			return fmt.Errorf("subgroup %q not found in metadata", name)
 		}
+		targetID := target.ID
+		name = target.Name
+
+		// The folder recorded in the metadata
+		localPath := filepath.Join(wd, target.Dir)
+		if sgLocalForceDelete {
+			if rel, err := filepath.Rel(localPath, ws.Dir); err == nil && !strings.HasPrefix(rel, "..") {
+				return fmt.Errorf("cannot delete local folder while inside it. Please cd %s and run again", wd)
+			}
+			if err := checkLocalWork(cmd.Context(), localPath); err != nil {
+				return err
+			}
+		}
 
 		fmt.Printf("Deleting subgroup %s (ID: %d)...\n", name, targetID)
 
 		// Check Empty
 		if !sgForceDelete {
-			prjs, _ := apiListProjects(targetID)
+			prjs, err := apiListProjects(cmd.Context(), targetID)
+			if err != nil {
+				return fmt.Errorf("check subgroup content failed: %w", err)
+			}
 			if len(prjs) > 0 {
 				return fmt.Errorf("subgroup is not empty (%d projects). Use -f to force", len(prjs))
 			}
+			sgs, err := apiListSubgroups(cmd.Context(), targetID)
+			if err != nil {
+				return fmt.Errorf("check subgroup content failed: %w", err)
+			}
+			if len(sgs) > 0 {
+				return fmt.Errorf("subgroup is not empty (%d subgroups). Use -f to force", len(sgs))
+			}
 		}
 
 		// API Delete
-		// API Delete
-		err := RunSpinner(fmt.Sprintf("Deleting subgroup %s (ID: %d)", name, targetID), func() error {
-			glabCmd := exec.Command("glab", "api", "-X", "DELETE", "/groups/"+strconv.FormatInt(targetID, 10))
-			if out, err := glabCmd.CombinedOutput(); err != nil {
-				return fmt.Errorf("gitlab delete failed: %s (%w)", string(out), err)
+		api, err := newGitLabClient()
+		if err != nil {
+			return err
+		}
+		err = RunSpinner(fmt.Sprintf("Deleting subgroup %s (ID: %d)", name, targetID), func() error {
+			if err := api.DeleteGroup(cmd.Context(), targetID); err != nil {
+				return fmt.Errorf("gitlab delete failed: %w", err)
 			}
 			return nil
 		})
@@ -83,25 +97,25 @@ Behavior:
 		}
 
 		// Update Meta
-		newSgs := []subgroupIdent{}
-		for _, sg := range meta.Subgroups {
-			if sg.ID != targetID {
-				newSgs = append(newSgs, sg)
+		err = updateLevelMeta(wd, func(meta *levelMeta) error {
+			newSgs := []subgroupIdent{}
+			for _, sg := range meta.Subgroups {
+				if sg.ID != targetID {
+					newSgs = append(newSgs, sg)
+				}
 			}
+			meta.Subgroups = newSgs
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("update %s metadata failed: %w", meta.kind(), err)
 		}
-		meta.Subgroups = newSgs
-		writeGroupJSON(filepath.Join(wd, ".ash"), meta)
 
 		// Local Delete
-		if sgLocalForceDelete {
-			// Try deleting folder with same name
-			localPath := filepath.Join(wd, name)
-			// fallback if name != path? usually name matches folder in our flow.
-			if !fileExists(localPath) && fileExists(filepath.Join(wd, targetPath)) {
-				localPath = filepath.Join(wd, targetPath)
+		if sgLocalForceDelete && fileExists(localPath) {
+			if err := removeDir(localPath, "subgroup", targetID, name); err != nil {
+				return fmt.Errorf("failed to remove local folder: %w", err)
 			}
-			os.RemoveAll(localPath)
-			fmt.Println("[DEL] Deleted local folder.")
 		}
 
 		return nil
@@ -112,4 +126,5 @@ func init() {
 	subgroupCmd.AddCommand(subgroupDeleteCmd)
 	subgroupDeleteCmd.Flags().BoolVarP(&sgForceDelete, "force", "f", false, "Force delete on GitLab")
 	subgroupDeleteCmd.Flags().BoolVarP(&sgLocalForceDelete, "local-force", "l", false, "Delete local folder")
+	subgroupDeleteCmd.Flags().BoolVar(&discardLocalWork, "discard-local-work", false, "With -l, delete the folder even if it holds uncommitted or unpushed work")
 }
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -11,23 +10,28 @@ import (
 
 var subgroupListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List subgroups in the current group",
+	Short: "List subgroups of the current group or subgroup",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		wd, err := os.Getwd()
+		ws, err := currentWorkspace()
 		if err != nil {
 			return err
 		}
-		groupMetaPath := filepath.Join(wd, ".ash", "group.json")
-
-		if !fileExists(groupMetaPath) {
-			return fmt.Errorf("not in a group root (.ash/group.json missing)")
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
 		}
-
-		var meta rootGroupMeta
-		if err := readJSON(groupMetaPath, &meta); err != nil {
+		meta, err := readLevelMeta(wd)
+		if err != nil {
 			return err
 		}
 
+		if structuredOutput() {
+			if meta.Subgroups == nil {
+				meta.Subgroups = []subgroupIdent{}
+			}
+			return writeStructured(meta.Subgroups)
+		}
+
 		if len(meta.Subgroups) == 0 {
 			fmt.Println("No subgroups found.")
 			return nil
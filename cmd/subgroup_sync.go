@@ -2,46 +2,72 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 )
 
-var sgSyncClean bool
+var (
+	sgSyncClean  bool
+	sgSyncDryRun bool
+)
 
 var subgroupSyncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync projects in the current subgroup",
+	Long: `Sync the current subgroup: update .ash/subgroup.json from GitLab, rename
+folders of renamed projects, clone new projects and pull existing ones.
+Nested subgroups are synced the same way, at any depth.
+Works from any folder inside the subgroup (e.g. from inside a project).
+
+With --dry-run, the full plan is printed and nothing is changed on disk or remotes.
+Failures (a clone, a pull, a folder rename, a subgroup) do not stop the sync;
+they are listed at the end and make the command exit with an error.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		wd, err := os.Getwd()
+		ws, err := currentWorkspace()
 		if err != nil {
 			return err
 		}
-		subMetaPath := filepath.Join(wd, ".ash", "subgroup.json")
-
-		if !fileExists(subMetaPath) {
-			return fmt.Errorf("not in a subgroup folder (.ash/subgroup.json missing)")
+		wd, err := ws.subgroupRoot()
+		if err != nil {
+			return err
 		}
+		subMetaPath := filepath.Join(wd, ".ash", "subgroup.json")
 
 		var meta subgroupMeta
-		if err := readJSON(subMetaPath, &meta); err != nil {
+		if err := readSubgroupMeta(subMetaPath, &meta); err != nil {
 			return err
 		}
 
-		fmt.Printf("Syncing Subgroup: %s (ID: %d)\n", meta.Group.Name, meta.Group.ID)
+		fmt.Fprintf(infoOut(), "Syncing Subgroup: %s (ID: %d)\n", meta.Group.Name, meta.Group.ID)
+
+		plan, err := planLevelSync(cmd.Context(), wd, wd, meta.Group, meta.level(), sgSyncClean, sgSyncDryRun)
+		if err != nil {
+			return err
+		}
+		if sgSyncDryRun {
+			fmt.Fprintln(infoOut())
+			plan.Print()
+			fmt.Fprintf(infoOut(), "\n%s[DRY-RUN] No changes made.%s\n", Yellow, Reset)
+			return nil
+		}
 
 		// Use the shared helper from group_sync.go
-		if err := syncSubgroupContent(wd, meta.Group.ID, sgSyncClean); err != nil {
+		fails := newSyncFailures(wd)
+		if err := fails.report(applyLevelSync(cmd.Context(), plan, fails)); err != nil {
 			return err
 		}
 
-		fmt.Printf("%s[OK] Sync complete.%s\n", Green, Reset)
+		fmt.Fprintf(infoOut(), "%s[OK] Sync complete.%s\n", Green, Reset)
 		return nil
 	},
 }
 
 func init() {
 	subgroupCmd.AddCommand(subgroupSyncCmd)
-	subgroupSyncCmd.Flags().BoolVar(&sgSyncClean, "clean", false, "Delete local folders of removed projects")
+	subgroupSyncCmd.Flags().BoolVar(&sgSyncClean, "clean", false, "Move local folders of removed projects and subgroups to the trash")
+	subgroupSyncCmd.Flags().BoolVar(&sgSyncDryRun, "dry-run", false, "Print the sync plan without changing anything")
+	subgroupSyncCmd.Flags().BoolVar(&discardLocalWork, "discard-local-work", false, "With --clean, delete orphan folders even if they hold uncommitted or unpushed work")
 }
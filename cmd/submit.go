@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/charmbracelet/huh"
@@ -20,40 +22,52 @@ var (
 var submitCmd = &cobra.Command{
 	Use:   "submit [folder...]",
 	Short: "Submit assignments",
+	Long: `Commit and push assignments of the current subgroup (or group root).
+Works from any folder inside the subgroup. Run inside a project folder without
+arguments to submit just that project; otherwise pick from a list, or use --all.
+
+Before pushing, submit fetches origin and rebases your commits onto anything
+pushed there meanwhile (teacher feedback, another machine). On a conflict the
+rebase is undone, nothing is pushed and the conflicting files are listed.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		wd, err := os.Getwd()
+		ws, err := currentWorkspace()
 		if err != nil {
 			return err
 		}
-		subMetaPath := filepath.Join(wd, ".ash", "subgroup.json")
-		if !fileExists(subMetaPath) {
-			return errors.New("not in a subgroup folder")
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
 		}
-
-		var meta subgroupMeta
-		readJSON(subMetaPath, &meta)
-		projectMap := make(map[string]projectIdent)
-		for _, p := range meta.Projects {
-			projectMap[p.Name] = p
+		meta, err := readLevelMeta(wd)
+		if err != nil {
+			return err
 		}
-
 		// 1. SELECT TARGETS
 		var targets []projectIdent
 
 		if len(args) > 0 {
 			// Select by name args
 			for _, name := range args {
-				if p, ok := projectMap[name]; ok {
+				if p, ok := findProject(meta.Projects, name); ok {
 					targets = append(targets, p)
 				} else {
 					// Fallback if local folder exists but not in meta
 					if fileExists(filepath.Join(wd, name)) {
-						targets = append(targets, projectIdent{Name: name})
+						targets = append(targets, projectIdent{Name: name, Dir: name})
 					}
 				}
 			}
 		} else if submitAll {
 			targets = meta.Projects
+		} else if ws.inProject() {
+			// Run from inside a project: submit just that one
+			target := projectIdent{Name: ws.Project, Dir: ws.Project}
+			for _, p := range meta.Projects {
+				if p.Dir == ws.Project {
+					target = p
+				}
+			}
+			targets = append(targets, target)
 		} else {
 			// Interactive UI
 			options := []huh.Option[string]{}
@@ -73,12 +87,17 @@ var submitCmd = &cobra.Command{
 				return nil
 			} // Cancelled
 			for _, s := range selected {
-				targets = append(targets, projectMap[s])
+				if p, ok := findProject(meta.Projects, s); ok {
+					targets = append(targets, p)
+				}
 			}
 		}
 
 		if len(targets) == 0 {
-			fmt.Printf("%s[INFO] No projects selected.%s\n", Yellow, Reset)
+			fmt.Fprintf(infoOut(), "%s[INFO] No projects selected.%s\n", Yellow, Reset)
+			if structuredOutput() {
+				PrintResults(nil)
+			}
 			return nil
 		}
 
@@ -95,14 +114,20 @@ var submitCmd = &cobra.Command{
 		var results []TaskResult
 		var mu sync.Mutex
 
+		ctx := cmd.Context()
 		title := fmt.Sprintf("Submitting %d project(s)...", len(targets))
 		err = RunSpinner(title, func() error {
 			var wg sync.WaitGroup
+			workers := gitPool()
 			for _, p := range targets {
 				wg.Add(1)
 				go func(proj projectIdent) {
 					defer wg.Done()
-					res := submitOneRepo(wd, proj.Name, finalMsg)
+					res := cancelledResult(proj.Name)
+					if workers.acquire(ctx) {
+						res = submitOneRepo(ctx, filepath.Join(wd, proj.Dir), proj.Name, finalMsg)
+						workers.release()
+					}
 					mu.Lock()
 					results = append(results, res)
 					mu.Unlock()
@@ -115,39 +140,207 @@ var submitCmd = &cobra.Command{
 			return err
 		}
 		PrintResults(results)
-		return nil
+		return ctx.Err()
 	},
 }
 
-func submitOneRepo(wd, name, msg string) TaskResult {
-	dir := filepath.Join(wd, name)
+// submitOneRepo commits the project checked out in dir, brings in what was
+// pushed to origin meanwhile (the teacher's feedback, another machine) and
+// pushes. A push rejected because origin moved again is retried.
+func submitOneRepo(ctx context.Context, dir, name, msg string) TaskResult {
+	if ctx.Err() != nil {
+		return cancelledResult(name)
+	}
 	if !fileExists(dir) {
 		return TaskResult{Name: name, Status: "ERR", Message: "Folder missing"}
 	}
 	if !fileExists(filepath.Join(dir, ".git")) {
 		return TaskResult{Name: name, Status: "ERR", Message: "Not a git repo"}
 	}
+	if op := gitOperationInProgress(ctx, dir); op != "" {
+		return TaskResult{Name: name, Status: "ERR", Message: fmt.Sprintf("A %s is in progress; finish or abort it, then submit again", op)}
+	}
 
 	// 1. Add
-	exec.Command("git", "-C", dir, "add", ".").Run()
+	gitCmd(ctx, "-C", dir, "add", ".").Run()
 
 	// 2. Check status
-	out, _ := exec.Command("git", "-C", dir, "status", "--porcelain").Output()
+	out, _ := gitCmd(ctx, "-C", dir, "status", "--porcelain").Output()
 	if len(out) > 0 {
 		// Commit
-		if err := exec.Command("git", "-C", dir, "commit", "-m", msg).Run(); err != nil {
+		if err := gitCmd(ctx, "-C", dir, "commit", "-m", msg).Run(); err != nil {
+			if ctx.Err() != nil {
+				return cancelledResult(name)
+			}
 			return TaskResult{Name: name, Status: "ERR", Message: "Commit failed"}
 		}
 	}
 
-	// 3. Push
-	if err := exec.Command("git", "-C", dir, "push", "--quiet").Run(); err != nil {
-		return TaskResult{Name: name, Status: "ERR", Message: "Push failed"}
+	// 3. Catch up with origin, then push
+	pulled := 0
+	for attempt := 1; ; attempt++ {
+		n, err := catchUpWithUpstream(ctx, dir)
+		pulled += n
+		if err != nil {
+			if ctx.Err() != nil {
+				return TaskResult{Name: name, Status: "CANCELLED", Message: "Committed locally, push interrupted"}
+			}
+			var conflict *rebaseConflict
+			if errors.As(err, &conflict) {
+				return TaskResult{Name: name, Status: "ERR", Message: conflict.Error()}
+			}
+			return TaskResult{Name: name, Status: "ERR", Message: "Committed locally, " + err.Error()}
+		}
+
+		out, err := pushCmd(ctx, dir).CombinedOutput()
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return TaskResult{Name: name, Status: "CANCELLED", Message: "Committed locally, push interrupted"}
+		}
+		if !pushRejected(out) || attempt == submitPushAttempts {
+			return TaskResult{Name: name, Status: "ERR", Message: pushFailure(out)}
+		}
+		// origin moved between our fetch and our push: catch up again
 	}
 
+	if pulled > 0 {
+		return TaskResult{Name: name, Status: "OK", Message: fmt.Sprintf("Submitted (brought in %d new commit(s) from origin)", pulled)}
+	}
 	return TaskResult{Name: name, Status: "OK", Message: "Submitted"}
 }
 
+// --- CATCHING UP WITH ORIGIN ---
+
+// submitPushAttempts is how many times a rejected push is tried.
+const submitPushAttempts = 3
+
+// rebaseConflict is a rebase onto the upstream that stopped on conflicts.
+// The rebase has been aborted, so the local commits are as they were.
+type rebaseConflict struct {
+	Upstream string
+	Files    []string
+}
+
+func (e *rebaseConflict) Error() string {
+	files := "some files"
+	if len(e.Files) > 0 {
+		files = strings.Join(e.Files, ", ")
+	}
+	return fmt.Sprintf("Committed locally, not pushed: %s has changes that conflict with yours in %s; run 'git pull --rebase', resolve them, then submit again", e.Upstream, files)
+}
+
+// catchUpWithUpstream fetches the upstream of the branch checked out in dir
+// and rebases the local commits onto it (a fast-forward when there are
+// none). It returns how many commits were brought in. A branch without an
+// upstream is left alone; pushCmd sets one.
+func catchUpWithUpstream(ctx context.Context, dir string) (int, error) {
+	up, err := gitCmd(ctx, "-C", dir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}").Output()
+	if err != nil {
+		return 0, nil
+	}
+	upstream := strings.TrimSpace(string(up))
+
+	if out, err := gitCmd(ctx, "-C", dir, "fetch", "--quiet").CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("fetch failed: %s", lastLine(out))
+	}
+	out, err := gitCmd(ctx, "-C", dir, "rev-list", "--count", "HEAD..@{u}").Output()
+	if err != nil {
+		return 0, fmt.Errorf("cannot compare with %s", upstream)
+	}
+	behind, _ := strconv.Atoi(strings.TrimSpace(string(out)))
+	if behind == 0 {
+		return 0, nil
+	}
+
+	if out, err := gitCmd(ctx, "-C", dir, "rebase", "--quiet", "@{u}").CombinedOutput(); err != nil {
+		// List the conflicts before the abort clears them. The abort runs
+		// even after Ctrl-C so the repository is never left mid-rebase.
+		files, _ := exec.Command("git", "-c", "core.quotePath=false", "-C", dir, "diff", "--name-only", "--diff-filter=U").Output()
+		exec.Command("git", "-C", dir, "rebase", "--abort").Run()
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		var conflicts []string // one per line: names may contain spaces
+		for _, f := range strings.Split(string(files), "\n") {
+			if f = strings.TrimSpace(f); f != "" {
+				conflicts = append(conflicts, f)
+			}
+		}
+		if len(conflicts) > 0 {
+			return 0, &rebaseConflict{Upstream: upstream, Files: conflicts}
+		}
+		return 0, fmt.Errorf("rebase onto %s failed: %s", upstream, lastLine(out))
+	}
+	return behind, nil
+}
+
+// pushCmd pushes the current branch; a branch without an upstream (a fresh
+// repository) gets origin's branch of the same name as its upstream.
+func pushCmd(ctx context.Context, dir string) *exec.Cmd {
+	if err := gitCmd(ctx, "-C", dir, "rev-parse", "--verify", "--quiet", "@{u}").Run(); err != nil {
+		return gitCmd(ctx, "-C", dir, "push", "--quiet", "--set-upstream", "origin", "HEAD")
+	}
+	return gitCmd(ctx, "-C", dir, "push", "--quiet")
+}
+
+// pushRejected reports whether git refused a push because the remote branch
+// has commits the local one lacks, or moved while the push was running.
+func pushRejected(out []byte) bool {
+	for _, s := range []string{"[rejected]", "non-fast-forward", "fetch first", "cannot lock ref"} {
+		if strings.Contains(string(out), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// pushFailure describes a failed push by git's " ! [rejected] ..." line for
+// the branch, else by its last line.
+func pushFailure(out []byte) string {
+	msg := "Push failed"
+	for _, ln := range strings.Split(string(out), "\n") {
+		if ln = strings.TrimSpace(ln); strings.HasPrefix(ln, "! [") {
+			return msg + ": " + strings.TrimSpace(strings.TrimPrefix(ln, "!"))
+		}
+	}
+	if l := lastLine(out); l != "" {
+		msg += ": " + l
+	}
+	return msg
+}
+
+// gitOperationInProgress names the rebase or merge left unfinished in dir,
+// if any; submitting on top of it would commit a half-done state.
+func gitOperationInProgress(ctx context.Context, dir string) string {
+	for _, op := range []struct{ path, name string }{
+		{"rebase-merge", "rebase"},
+		{"rebase-apply", "rebase"},
+		{"MERGE_HEAD", "merge"},
+	} {
+		out, err := gitCmd(ctx, "-C", dir, "rev-parse", "--git-path", op.path).Output()
+		if err != nil {
+			continue
+		}
+		p := strings.TrimSpace(string(out))
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		if fileExists(p) {
+			return op.name
+		}
+	}
+	return ""
+}
+
+// lastLine is the last non-empty line of git's output, which usually says
+// what went wrong.
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
 func init() {
 	rootCmd.AddCommand(submitCmd)
 	submitCmd.Flags().BoolVar(&submitAll, "all", false, "Submit all")
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// submitRepos sets up an origin with one commit, a student clone and a
+// teacher clone, both up to date. It returns the three paths.
+func submitRepos(t *testing.T) (origin, student, teacher string) {
+	t.Helper()
+	isolateGit(t)
+	root := t.TempDir()
+	origin = filepath.Join(root, "origin.git")
+	student = filepath.Join(root, "student")
+	teacher = filepath.Join(root, "teacher")
+	git(t, root, "init", "--quiet", "--bare", "--initial-branch=main", origin)
+	git(t, root, "clone", "--quiet", origin, teacher)
+	writeFile(t, filepath.Join(teacher, "Bai Tap 1.java"), "class BaiTap1 {}\n")
+	git(t, teacher, "add", ".")
+	git(t, teacher, "commit", "--quiet", "-m", "Starter code")
+	git(t, teacher, "push", "--quiet", "origin", "HEAD:main")
+	git(t, root, "clone", "--quiet", origin, student)
+	return origin, student, teacher
+}
+
+// isolateGit skips the test without git, and runs git with a throwaway
+// HOME and a fixed identity.
+func isolateGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "t")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "t@example.com")
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitAbortsConflictingRebase(t *testing.T) {
+	origin, student, teacher := submitRepos(t)
+	writeFile(t, filepath.Join(teacher, "Bai Tap 1.java"), "class BaiTap1 { /* feedback */ }\n")
+	git(t, teacher, "commit", "--quiet", "-am", "Feedback")
+	git(t, teacher, "push", "--quiet")
+	feedback := git(t, origin, "rev-parse", "main")
+
+	writeFile(t, filepath.Join(student, "Bai Tap 1.java"), "class BaiTap1 { int answer; }\n")
+	res := submitOneRepo(t.Context(), student, "Lab1", "My answer")
+
+	if res.Status != "ERR" || !strings.Contains(res.Message, "conflict with yours in Bai Tap 1.java;") {
+		t.Errorf("result = %+v, want ERR naming Bai Tap 1.java", res)
+	}
+	for _, d := range []string{"rebase-merge", "rebase-apply"} {
+		if fileExists(filepath.Join(student, ".git", d)) {
+			t.Errorf("rebase left in progress (.git/%s)", d)
+		}
+	}
+	if msg := git(t, student, "log", "-1", "--format=%s"); msg != "My answer" {
+		t.Errorf("student HEAD is %q, want the local commit", msg)
+	}
+	if b, _ := os.ReadFile(filepath.Join(student, "Bai Tap 1.java")); !strings.Contains(string(b), "answer") {
+		t.Errorf("student work lost: %s", b)
+	}
+	if st := git(t, student, "status", "--porcelain"); st != "" {
+		t.Errorf("work tree not clean:\n%s", st)
+	}
+	if head := git(t, origin, "rev-parse", "main"); head != feedback {
+		t.Errorf("origin moved to %s, want nothing pushed", head)
+	}
+}
+
+func TestSubmitBringsInUpstreamCommits(t *testing.T) {
+	origin, student, teacher := submitRepos(t)
+	writeFile(t, filepath.Join(teacher, "README.md"), "Due Friday\n")
+	git(t, teacher, "add", ".")
+	git(t, teacher, "commit", "--quiet", "-m", "Deadline")
+	git(t, teacher, "push", "--quiet")
+
+	writeFile(t, filepath.Join(student, "Bai Tap 1.java"), "class BaiTap1 { int answer; }\n")
+	res := submitOneRepo(t.Context(), student, "Lab1", "My answer")
+
+	if res.Status != "OK" || !strings.Contains(res.Message, "brought in 1 new commit") {
+		t.Errorf("result = %+v, want OK with 1 commit brought in", res)
+	}
+	if log := git(t, origin, "log", "--format=%s", "main"); log != "My answer\nDeadline\nStarter code" {
+		t.Errorf("origin history:\n%s", log)
+	}
+}
@@ -0,0 +1,436 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// --- SYNC PLAN ---
+// A sync is computed as a plan first (GitLab reads + local inspection only),
+// then applied. `--dry-run` prints the plan and stops there.
+
+type renameOp struct {
+	From string
+	To   string
+	ID   int64 // the renamed subgroup or project
+
+	// GitLab name and path before the rename
+	OldName, OldPath string
+}
+
+// syncRepo is one project repository the sync will clone or pull.
+type syncRepo struct {
+	Name string
+	URL  string
+	Dir  string // final location (after renames)
+}
+
+// levelSyncPlan describes the changes for one level of the hierarchy: the
+// group root or a subgroup at any depth. Each level may hold both subgroups
+// and projects; nested subgroups get their own plan.
+type levelSyncPlan struct {
+	SrcDir string // where the folder lives now
+	Dir    string // where it will live after the parent sync (differs on rename)
+	Clean  bool
+
+	Meta        levelMeta // metadata that will be written
+	MetaChanges []string  // human-readable metadata diff
+
+	// Subgroups of this level
+	SubgroupsAdded   []string
+	SubgroupsRemoved []string
+	Ignored          []string // soft-deleted on GitLab
+	SubgroupRenames  []renameOp
+	Scaffold         []subgroupIdent  // subgroup folders that will be created
+	Children         []*levelSyncPlan // nested plans (recursive planning only)
+
+	// Projects of this level
+	Added   []string
+	Removed []string
+	Renames []renameOp
+	Clones  []syncRepo
+	Pulls   []syncRepo
+	Skips   []string // folders that exist but are not git repos
+
+	Orphans []string // folders matching no remote subgroup or project
+	// OrphanWork maps orphan folders to their local git work (with --clean only)
+	OrphanWork map[string]string
+	OrphanIDs  map[string]int64  // IDs of orphans known from the old metadata
+	OrphanKind map[string]string // "subgroup" or "project"
+}
+
+// planLevelSync computes the sync plan for the level whose folder is srcDir
+// now and will be dir once the parent sync has renamed it. meta is the
+// level's current metadata and group its identity on GitLab as far as the
+// caller knows it (the parent's listing for subgroups). With recursive set,
+// every nested subgroup is planned as well (used by --dry-run); otherwise
+// applyLevelSync plans each one when it gets there.
+func planLevelSync(ctx context.Context, srcDir, dir string, group groupIdent, meta levelMeta, clean, recursive bool) (*levelSyncPlan, error) {
+	// 1. Fetch both kinds of children
+	sgs, err := apiListSubgroups(ctx, group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subgroups: %w", err)
+	}
+	prjs, err := apiListProjects(ctx, group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %w", err)
+	}
+
+	plan := &levelSyncPlan{SrcDir: srcDir, Dir: dir, Clean: clean}
+	newMeta := levelMeta{Root: meta.Root, Group: group, Template: meta.Template}
+
+	// Name/Path may be empty in older metadata; fill them from GitLab.
+	if group.Name == "" || group.Path == "" {
+		if g, err := apiGetGroup(ctx, group.ID); err == nil {
+			newMeta.Group.Name = g.Name
+			newMeta.Group.Path = g.Path
+		}
+	}
+	if meta.Group.ID != newMeta.Group.ID {
+		plan.MetaChanges = append(plan.MetaChanges, fmt.Sprintf("group.id: %d -> %d", meta.Group.ID, newMeta.Group.ID))
+	}
+	if meta.Group.Name != newMeta.Group.Name {
+		plan.MetaChanges = append(plan.MetaChanges, fmt.Sprintf("group.name: %q -> %q", meta.Group.Name, newMeta.Group.Name))
+	}
+	if meta.Group.Path != newMeta.Group.Path {
+		plan.MetaChanges = append(plan.MetaChanges, fmt.Sprintf("group.path: %q -> %q", meta.Group.Path, newMeta.Group.Path))
+	}
+
+	renamedFrom := make(map[string]bool)
+
+	// 2. Subgroups: filter out Marked For Deletion groups, then diff
+	var validRemoteSGs []glGroup
+	for _, sg := range sgs {
+		if sg.MarkedForDeletionOn == "" {
+			validRemoteSGs = append(validRemoteSGs, sg)
+		} else {
+			plan.Ignored = append(plan.Ignored, sg.Name)
+		}
+	}
+	remoteSGs := make(map[int64]glGroup)
+	for _, sg := range validRemoteSGs {
+		remoteSGs[sg.ID] = sg
+	}
+	oldSGs := make(map[int64]subgroupIdent)
+	for _, old := range meta.Subgroups {
+		oldSGs[old.ID] = old
+	}
+	srcOf := make(map[int64]string) // subgroup ID -> folder before renames
+	dirOf := make(map[int64]string) // subgroup or project ID -> folder after renames
+	for _, oldSg := range meta.Subgroups {
+		newSg, ok := remoteSGs[oldSg.ID]
+		if !ok {
+			// Removed or Soft-Deleted: the orphan scan handles the folder.
+			plan.SubgroupsRemoved = append(plan.SubgroupsRemoved, oldSg.Name)
+			continue
+		}
+		to := followRename(oldSg.Dir, oldSg.Name, oldSg.Path, newSg.Name, newSg.Path)
+		dirOf[oldSg.ID] = to
+		srcOf[oldSg.ID] = to
+		if to != oldSg.Dir && fileExists(filepath.Join(srcDir, oldSg.Dir)) {
+			plan.SubgroupRenames = append(plan.SubgroupRenames, renameOp{From: oldSg.Dir, To: to, ID: oldSg.ID, OldName: oldSg.Name, OldPath: oldSg.Path})
+			renamedFrom[oldSg.Dir] = true
+			srcOf[oldSg.ID] = oldSg.Dir
+		}
+	}
+	newMeta.Subgroups = []subgroupIdent{}
+	for _, sg := range validRemoteSGs {
+		ident := newSubgroupIdent(sg)
+		if d, ok := dirOf[sg.ID]; ok {
+			ident.Dir = d
+		} else {
+			plan.SubgroupsAdded = append(plan.SubgroupsAdded, sg.Name)
+		}
+		newMeta.Subgroups = append(newMeta.Subgroups, ident)
+	}
+
+	// 3. Projects: detect Removed / Renamed
+	remotePrjs := make(map[int64]glProject)
+	for _, p := range prjs {
+		remotePrjs[p.ID] = p
+	}
+	oldPrjs := make(map[int64]projectIdent)
+	for _, old := range meta.Projects {
+		oldPrjs[old.ID] = old
+	}
+	for _, old := range meta.Projects {
+		newP, ok := remotePrjs[old.ID]
+		if !ok {
+			plan.Removed = append(plan.Removed, old.Name)
+			continue
+		}
+		to := followRename(old.Dir, old.Name, old.Path, newP.Name, newP.Path)
+		dirOf[old.ID] = to
+		if to != old.Dir && fileExists(filepath.Join(srcDir, old.Dir)) {
+			plan.Renames = append(plan.Renames, renameOp{From: old.Dir, To: to, ID: old.ID, OldName: old.Name, OldPath: old.Path})
+			renamedFrom[old.Dir] = true
+		}
+	}
+	newMeta.Projects = []projectIdent{}
+	for _, p := range prjs {
+		ident := newProjectIdent(p)
+		if d, ok := dirOf[p.ID]; ok {
+			ident.Dir = d
+		} else {
+			plan.Added = append(plan.Added, p.Name)
+		}
+		newMeta.Projects = append(newMeta.Projects, ident)
+	}
+	for _, p := range prjs {
+		if old, ok := oldPrjs[p.ID]; ok && old.Path != p.Path {
+			plan.MetaChanges = append(plan.MetaChanges, fmt.Sprintf("project %s path: %q -> %q", p.Name, old.Path, p.Path))
+		}
+	}
+	plan.Meta = newMeta
+
+	// 4. Orphan scan: any folder (after renames) that is neither a remote
+	// subgroup nor a remote project. This covers cases where metadata was
+	// already updated but folders weren't deleted.
+	validNames := make(map[string]bool)
+	for _, sg := range newMeta.Subgroups {
+		validNames[sg.Dir] = true
+	}
+	for _, p := range newMeta.Projects {
+		validNames[p.Dir] = true
+	}
+	for _, name := range localSubdirs(srcDir) {
+		if !validNames[name] && !renamedFrom[name] {
+			plan.Orphans = append(plan.Orphans, name)
+		}
+	}
+	plan.OrphanWork = orphanWork(ctx, srcDir, plan.Orphans, clean)
+	plan.OrphanIDs = make(map[string]int64)
+	plan.OrphanKind = make(map[string]string)
+	for _, name := range plan.Orphans {
+		plan.OrphanKind[name] = "project"
+		if fileExists(filepath.Join(srcDir, name, ".ash", "subgroup.json")) {
+			plan.OrphanKind[name] = "subgroup"
+		}
+	}
+	for _, old := range meta.Projects {
+		plan.OrphanIDs[old.Dir] = old.ID
+	}
+	for _, old := range meta.Subgroups {
+		plan.OrphanIDs[old.Dir] = old.ID
+		plan.OrphanKind[old.Dir] = "subgroup"
+	}
+
+	// 5. Subgroup folders to scaffold
+	for _, sg := range newMeta.Subgroups {
+		src, ok := srcOf[sg.ID]
+		if !ok {
+			src = sg.Dir
+		}
+		if !fileExists(filepath.Join(srcDir, src)) {
+			plan.Scaffold = append(plan.Scaffold, sg)
+		}
+	}
+
+	// 6. Clone / Pull
+	proto := configuredProto()
+	renamedTo := make(map[string]string)
+	for _, r := range plan.Renames {
+		renamedTo[r.To] = r.From
+	}
+	for i, p := range prjs {
+		url := p.HTTPURLToRepo
+		if proto == "ssh" {
+			url = p.SSHURLToRepo
+		}
+		folder := newMeta.Projects[i].Dir
+		repo := syncRepo{Name: p.Name, URL: url, Dir: filepath.Join(dir, folder)}
+
+		// Where the folder is right now
+		current := filepath.Join(srcDir, folder)
+		if from, ok := renamedTo[folder]; ok {
+			current = filepath.Join(srcDir, from)
+		}
+		switch {
+		case !fileExists(current):
+			plan.Clones = append(plan.Clones, repo)
+		case fileExists(filepath.Join(current, ".git")):
+			plan.Pulls = append(plan.Pulls, repo)
+		default:
+			plan.Skips = append(plan.Skips, folder)
+		}
+	}
+
+	// 7. Nested subgroups
+	if recursive {
+		for _, sg := range newMeta.Subgroups {
+			src, ok := srcOf[sg.ID]
+			if !ok {
+				src = sg.Dir
+			}
+			child, err := planLevelSync(ctx, filepath.Join(srcDir, src), filepath.Join(dir, sg.Dir), sg.group(), childMeta(filepath.Join(srcDir, src)), clean, true)
+			if err != nil {
+				return nil, fmt.Errorf("plan subgroup %s: %w", sg.Name, err)
+			}
+			plan.Children = append(plan.Children, child)
+		}
+	}
+
+	return plan, nil
+}
+
+// childMeta reads the subgroup.json of a nested subgroup folder
+// (missing or unreadable = empty, the sync rebuilds it).
+func childMeta(dir string) levelMeta {
+	var meta subgroupMeta
+	_ = readSubgroupMeta(filepath.Join(dir, ".ash", "subgroup.json"), &meta)
+	return meta.level()
+}
+
+// localSubdirs lists the non-hidden subfolders of dir, sorted by name.
+// Dot folders (.ash, .git, .vscode, .github...) are never a subgroup or a
+// project: GitLab names and paths cannot start with a dot.
+func localSubdirs(dir string) []string {
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// orphanWork inspects orphan folders that --clean would delete for local git work.
+func orphanWork(ctx context.Context, dir string, orphans []string, clean bool) map[string]string {
+	work := make(map[string]string)
+	if !clean {
+		return work
+	}
+	for _, name := range orphans {
+		if s := localWorkSummary(ctx, filepath.Join(dir, name)); s != "" {
+			work[name] = s
+		}
+	}
+	return work
+}
+
+// configuredProto returns the git protocol saved in config (default https).
+func configuredProto() string {
+	cfg, _, _ := loadConfig()
+	if cfg.GitProtocol != "" {
+		return cfg.GitProtocol
+	}
+	return "https"
+}
+
+// --- PLAN OUTPUT ---
+
+// keepSubgroupFolder records the subgroup renamed by r as it was before,
+// after its folder could not be renamed, so the next sync tries again.
+func (p *levelSyncPlan) keepSubgroupFolder(r renameOp) {
+	for i, sg := range p.Meta.Subgroups {
+		if sg.ID == r.ID {
+			p.Meta.Subgroups[i].Name, p.Meta.Subgroups[i].Path, p.Meta.Subgroups[i].Dir = r.OldName, r.OldPath, r.From
+		}
+	}
+}
+
+// keepProjectFolder records the project renamed by r as it was before, and
+// pulls it in its old folder, after the folder could not be renamed; the
+// next sync tries again.
+func (p *levelSyncPlan) keepProjectFolder(r renameOp) {
+	for i, prj := range p.Meta.Projects {
+		if prj.ID == r.ID {
+			p.Meta.Projects[i].Name, p.Meta.Projects[i].Path, p.Meta.Projects[i].Dir = r.OldName, r.OldPath, r.From
+		}
+	}
+	for i := range p.Pulls {
+		if p.Pulls[i].Dir == filepath.Join(p.Dir, r.To) {
+			p.Pulls[i].Dir = filepath.Join(p.Dir, r.From)
+		}
+	}
+}
+
+func (p *levelSyncPlan) Print() {
+	name := p.Meta.Group.Name
+	if name == "" {
+		name = filepath.Base(p.Dir)
+	}
+	fmt.Fprintf(infoOut(), "Plan for %s %s (%s)\n", p.Meta.kind(), name, p.Dir)
+	printPlanLines(p.MetaChanges, Yellow, "[META]")
+	printPlanLines(p.SubgroupsAdded, Cyan, "[NEW]", "subgroup")
+	printPlanLines(p.SubgroupsRemoved, Red, "[GONE]", "subgroup removed on GitLab")
+	printPlanLines(p.Ignored, Yellow, "[SKIP]", "soft-deleted on GitLab")
+	printPlanLines(p.Added, Cyan, "[NEW]", "project")
+	printPlanLines(p.Removed, Red, "[GONE]", "project removed on GitLab")
+	for _, r := range append(append([]renameOp{}, p.SubgroupRenames...), p.Renames...) {
+		fmt.Fprintf(infoOut(), "  %s%-8s %s -> %s (rename folder)%s\n", Yellow, "[REN]", r.From, r.To, Reset)
+	}
+	for _, sg := range p.Scaffold {
+		fmt.Fprintf(infoOut(), "  %s%-8s %s (create folder + .ash/subgroup.json)%s\n", Cyan, "[MKDIR]", sg.Dir, Reset)
+	}
+	printOrphanLines(p.Orphans, p.OrphanWork, p.Clean)
+	fmt.Fprintf(infoOut(), "  %s%-8s %s (%s)%s\n", Gray, "[WRITE]", p.metaFile(), p.metaCounts(), Reset)
+	for _, r := range p.Clones {
+		fmt.Fprintf(infoOut(), "  %s%-8s %s <- %s%s\n", Cyan, "[CLONE]", r.Name, r.URL, Reset)
+	}
+	for _, r := range p.Pulls {
+		fmt.Fprintf(infoOut(), "  %s%-8s %s%s\n", Green, "[PULL]", r.Name, Reset)
+	}
+	printPlanLines(p.Skips, Yellow, "[SKIP]", "folder exists but not git repo")
+
+	for _, child := range p.Children {
+		fmt.Fprintln(infoOut())
+		child.Print()
+	}
+}
+
+// metaFile is the metadata file the plan writes, relative to its folder.
+func (p *levelSyncPlan) metaFile() string {
+	if p.Meta.Root {
+		return filepath.Join(".ash", "group.json")
+	}
+	return filepath.Join(".ash", "subgroup.json")
+}
+
+// metaCounts summarizes the written metadata, the level's usual list first.
+func (p *levelSyncPlan) metaCounts() string {
+	sgs := fmt.Sprintf("%d subgroups", len(p.Meta.Subgroups))
+	prjs := fmt.Sprintf("%d projects", len(p.Meta.Projects))
+	switch {
+	case p.Meta.Root && len(p.Meta.Projects) == 0:
+		return sgs
+	case p.Meta.Root:
+		return sgs + ", " + prjs
+	case len(p.Meta.Subgroups) == 0:
+		return prjs
+	default:
+		return prjs + ", " + sgs
+	}
+}
+
+func printOrphanLines(orphans []string, work map[string]string, clean bool) {
+	if !clean {
+		printPlanLines(orphans, Yellow, "[ORPHAN]", "kept, use --clean to remove")
+		return
+	}
+	for _, name := range orphans {
+		if w, ok := work[name]; ok {
+			fmt.Fprintf(infoOut(), "  %s%-8s %s (orphan with local work: %s; needs confirmation or --discard-local-work)%s\n", Yellow, "[GUARD]", name, w, Reset)
+			continue
+		}
+		printPlanLines([]string{name}, Red, "[DEL]", "orphan folder will be moved to trash")
+	}
+}
+
+// printPlanLines prints one tagged line per item, with an optional note.
+func printPlanLines(items []string, color, tag string, note ...string) {
+	suffix := ""
+	if len(note) > 0 {
+		suffix = " (" + strings.Join(note, ", ") + ")"
+	}
+	for _, it := range items {
+		fmt.Fprintf(infoOut(), "  %s%-8s %s%s%s\n", color, tag, it, suffix, Reset)
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLocalSubdirsSkipsDotFolders(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{".ash", ".git", ".vscode", ".github", "Lab1", "Session 1"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Lab1", "Session 1"}
+	if got := localSubdirs(dir); !slices.Equal(got, want) {
+		t.Errorf("localSubdirs = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage starter templates for new projects",
+	Long: `A template seeds every project created by 'ash project create' with starter
+files (README, .gitignore, skeleton source), committed and pushed as the
+first commit.
+
+A template is a folder under ~/.config/ash/templates/<name>/, any other folder,
+or a git URL (its default branch is used). In text files, {{name}}, {{path}}
+and {{group}} are replaced by the project name, the project path and the name
+of the group or subgroup it is created in.
+
+Each group or subgroup may select a template in its .ash metadata
+('ash template set'); nested subgroups without one use their parent's.`,
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+}
+
+// --- TEMPLATE STORAGE ---
+
+// templatesDir is ~/.config/ash/templates, next to config.json.
+func templatesDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "ash", "templates"), nil
+}
+
+// isGitURL reports whether a template spec names a remote repository.
+func isGitURL(spec string) bool {
+	return strings.Contains(spec, "://") || strings.HasPrefix(spec, "git@") || strings.HasSuffix(spec, ".git")
+}
+
+// templateDirSpec resolves a spec that is not a git URL to a folder: a
+// template name under templatesDir(), else a path (~ allowed).
+func templateDirSpec(spec string) (string, error) {
+	if isTemplateName(spec) {
+		dir, _ := templatesDir()
+		return filepath.Join(dir, spec), nil
+	}
+	p := spec
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+	if isDir(p) {
+		return filepath.Abs(p)
+	}
+	return "", fmt.Errorf("template %q not found: not a name in ~/.config/ash/templates (see 'ash template list'), a folder or a git URL", spec)
+}
+
+// isTemplateName reports whether spec names a folder under templatesDir().
+func isTemplateName(spec string) bool {
+	if spec == "" || strings.ContainsAny(spec, `/\`) || spec == "." || spec == ".." {
+		return false
+	}
+	dir, err := templatesDir()
+	return err == nil && isDir(filepath.Join(dir, spec))
+}
+
+func isDir(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && fi.IsDir()
+}
+
+// projectTemplate is a template ready to be copied into new projects.
+type projectTemplate struct {
+	Spec string // as given by the user or the metadata
+	Dir  string // local folder holding the files
+
+	tmp string // clone of a git URL, removed by cleanup
+}
+
+// loadTemplate resolves spec, cloning a git URL once into a temporary folder.
+// Call cleanup when done.
+func loadTemplate(ctx context.Context, spec string) (*projectTemplate, error) {
+	if !isGitURL(spec) {
+		dir, err := templateDirSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		return &projectTemplate{Spec: spec, Dir: dir}, nil
+	}
+	tmp, err := os.MkdirTemp("", "ash-template-")
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(tmp, "repo")
+	if out, err := gitCmd(ctx, "clone", "--quiet", "--depth", "1", spec, dir).CombinedOutput(); err != nil {
+		os.RemoveAll(tmp)
+		return nil, fmt.Errorf("clone template %s: %v: %s", spec, err, strings.TrimSpace(string(out)))
+	}
+	return &projectTemplate{Spec: spec, Dir: dir, tmp: tmp}, nil
+}
+
+func (t *projectTemplate) cleanup() {
+	if t != nil && t.tmp != "" {
+		os.RemoveAll(t.tmp)
+	}
+}
+
+// levelTemplate returns the template selected for new projects in the level
+// folder dir: its own, else the nearest parent level's up to the group root.
+// from is the level that selects it ("" if none does).
+func levelTemplate(dir string) (spec, from string) {
+	for {
+		meta, err := readLevelMeta(dir)
+		if err != nil {
+			return "", ""
+		}
+		if meta.Template != "" {
+			return meta.Template, dir
+		}
+		if meta.Root {
+			return "", ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// --- SEEDING ---
+
+// seed copies the template into the freshly cloned, empty project at dest,
+// substituting the placeholders in text files, then commits and pushes the
+// result as the first commit.
+func (t *projectTemplate) seed(ctx context.Context, dest string, vars map[string]string) error {
+	var pairs []string
+	for k, v := range vars {
+		pairs = append(pairs, "{{"+k+"}}", v)
+	}
+	repl := strings.NewReplacer(pairs...)
+
+	err := filepath.WalkDir(t.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(t.Dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil // symlinks and the like are not copied
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if utf8.Valid(b) && !bytes.ContainsRune(b, 0) {
+			b = []byte(repl.Replace(string(b)))
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, b, info.Mode().Perm())
+	})
+	if err != nil {
+		return fmt.Errorf("copy template: %w", err)
+	}
+
+	steps := [][]string{
+		{"add", "--all"},
+		{"commit", "--quiet", "--allow-empty", "--message", "Initial commit from template " + strings.TrimSuffix(filepath.Base(t.Spec), ".git")},
+		{"push", "--quiet", "--set-upstream", "origin", "HEAD"},
+	}
+	for _, args := range steps {
+		if out, err := gitCmd(ctx, append([]string{"-C", dest}, args...)...).CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// templateInfo is one row of `ash template list`.
+type templateInfo struct {
+	Name    string `json:"name" yaml:"name"`
+	Path    string `json:"path" yaml:"path"`
+	Current bool   `json:"current" yaml:"current"` // selected for the current folder
+}
+
+var templateListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List templates in ~/.config/ash/templates",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := templatesDir()
+		if err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		// The template selected where we stand, if any
+		current, from := "", ""
+		if ws, err := currentWorkspace(); err == nil && ws.Level != "" {
+			current, from = levelTemplate(ws.Level)
+		}
+
+		infos := []templateInfo{}
+		for _, e := range entries {
+			if e.IsDir() {
+				infos = append(infos, templateInfo{Name: e.Name(), Path: filepath.Join(dir, e.Name()), Current: e.Name() == current})
+			}
+		}
+
+		if structuredOutput() {
+			return writeStructured(infos)
+		}
+
+		if len(infos) == 0 {
+			fmt.Printf("No templates in %s.\n", dir)
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tPATH")
+			for _, t := range infos {
+				name := t.Name
+				if t.Current {
+					name += " *"
+				}
+				fmt.Fprintf(w, "%s\t%s\n", name, t.Path)
+			}
+			w.Flush()
+		}
+		if current != "" {
+			fmt.Printf("\nNew projects here use template %s (set in %s).\n", current, from)
+		}
+		return nil
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateListCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var templateSetClear bool
+
+var templateSetCmd = &cobra.Command{
+	Use:   "set [name|dir|git-url]",
+	Short: "Select the template for new projects in the current group or subgroup",
+	Long: `Record a template in the .ash metadata of the current level (the group root
+or the subgroup you are in). 'ash project create' then seeds new projects with
+it unless --template or --no-template is given. Nested subgroups without a
+template of their own use this one.
+
+Folders are stored as absolute paths; names refer to ~/.config/ash/templates.`,
+	Example: `  ash template set java-lab
+  ash template set ~/templates/c-skeleton
+  ash template set https://gitlab.example.com/teachers/lab-template.git
+  ash template set --clear`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if templateSetClear == (len(args) == 1) {
+			return fmt.Errorf("pass a template, or --clear to remove it")
+		}
+		ws, err := currentWorkspace()
+		if err != nil {
+			return err
+		}
+		wd, err := ws.levelRoot()
+		if err != nil {
+			return err
+		}
+
+		spec := ""
+		if len(args) == 1 {
+			spec = args[0]
+			if !isGitURL(spec) {
+				dir, err := templateDirSpec(spec)
+				if err != nil {
+					return err
+				}
+				if !isTemplateName(spec) {
+					spec = dir // keep folders valid from anywhere
+				}
+			}
+		}
+
+		var name string
+		err = updateLevelMeta(wd, func(meta *levelMeta) error {
+			meta.Template = spec
+			name = meta.Group.Name
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if spec == "" {
+			fmt.Printf("%s[OK] Template removed from %s.%s\n", Green, name, Reset)
+			if inherited, from := levelTemplate(wd); inherited != "" {
+				fmt.Printf("New projects here still use template %s (set in %s).\n", inherited, from)
+			}
+			return nil
+		}
+		fmt.Printf("%s[OK] New projects in %s use template %s.%s\n", Green, name, spec, Reset)
+		return nil
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateSetCmd)
+	templateSetCmd.Flags().BoolVar(&templateSetClear, "clear", false, "Remove the template of the current level")
+}
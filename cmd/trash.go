@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore or purge folders removed by ash",
+	Long: `Folders removed by 'sync --clean' and 'delete -l' are not deleted right away:
+they are moved into .ash/trash/<id>/ of the workspace (the group root), together
+with a manifest recording where they came from and which command removed them.`,
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+}
+
+// --- TRASH STORAGE ---
+
+const trashManifest = "manifest.json"
+
+// trashEntry is the manifest of one removed folder: .ash/trash/<id>/manifest.json
+type trashEntry struct {
+	ID        string    `json:"id" yaml:"id"`
+	Original  string    `json:"original_path" yaml:"original_path"` // relative to the trash root when possible
+	Kind      string    `json:"kind" yaml:"kind"`                   // group | subgroup | project | folder
+	EntityID  int64     `json:"entity_id,omitempty" yaml:"entity_id,omitempty"`
+	Name      string    `json:"name" yaml:"name"`
+	Command   string    `json:"command" yaml:"command"`
+	RemovedAt time.Time `json:"removed_at" yaml:"removed_at"`
+
+	root string // workspace the entry belongs to (not serialized)
+}
+
+// dataPath is where the removed folder is kept inside the entry.
+func (e trashEntry) dataPath() string {
+	return filepath.Join(trashDir(e.root), e.ID, "data")
+}
+
+// originalPath resolves the folder location the entry was removed from.
+func (e trashEntry) originalPath() string {
+	if filepath.IsAbs(e.Original) {
+		return e.Original
+	}
+	return filepath.Join(e.root, e.Original)
+}
+
+func trashDir(root string) string {
+	return filepath.Join(root, ".ash", "trash")
+}
+
+// findTrashRoot walks up from start to the nearest group root (or folder that
+// already has a trash). Without one, start itself is used.
+func findTrashRoot(start string) string {
+	for dir := start; ; {
+		if fileExists(filepath.Join(dir, ".ash", "group.json")) || fileExists(trashDir(dir)) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return start
+		}
+		dir = parent
+	}
+}
+
+// moveToTrash moves path into the workspace trash instead of deleting it.
+// kind/entityID/name describe what the folder held (entityID may be 0).
+func moveToTrash(path, kind string, entityID int64, name string) (trashEntry, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return trashEntry{}, err
+	}
+	root := findTrashRoot(filepath.Dir(abs))
+
+	entry := trashEntry{
+		Kind:      kind,
+		EntityID:  entityID,
+		Name:      name,
+		Command:   strings.Join(append([]string{"ash"}, os.Args[1:]...), " "),
+		RemovedAt: time.Now(),
+		root:      root,
+	}
+	entry.Original = abs
+	if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		entry.Original = rel
+	}
+
+	// Claim a unique <timestamp>[-n] directory (concurrent syncs may collide)
+	if err := os.MkdirAll(trashDir(root), 0o755); err != nil {
+		return trashEntry{}, err
+	}
+	base := entry.RemovedAt.Format("20060102-150405")
+	for n := 1; ; n++ {
+		entry.ID = base
+		if n > 1 {
+			entry.ID = fmt.Sprintf("%s-%d", base, n)
+		}
+		err := os.Mkdir(filepath.Join(trashDir(root), entry.ID), 0o755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return trashEntry{}, err
+		}
+	}
+
+	entryDir := filepath.Join(trashDir(root), entry.ID)
+	if err := writeJSON(filepath.Join(entryDir, trashManifest), entry); err != nil {
+		os.RemoveAll(entryDir)
+		return trashEntry{}, err
+	}
+	if err := os.Rename(abs, entry.dataPath()); err != nil {
+		os.RemoveAll(entryDir)
+		return trashEntry{}, fmt.Errorf("move %s to trash: %w", abs, err)
+	}
+	return entry, nil
+}
+
+// removeDir moves dir to the trash and reports it; kind/entityID/name as in moveToTrash.
+func removeDir(dir, kind string, entityID int64, name string) error {
+	entry, err := moveToTrash(dir, kind, entityID, name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(infoOut(), "%s[DEL] Moved %s to trash (restore with 'ash trash restore %s')%s\n", Red, dir, entry.ID, Reset)
+	return nil
+}
+
+// loadTrash reads all entries of the trash at root, oldest first.
+func loadTrash(root string) ([]trashEntry, error) {
+	dirs, err := os.ReadDir(trashDir(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []trashEntry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		var e trashEntry
+		if err := readJSON(filepath.Join(trashDir(root), d.Name(), trashManifest), &e); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[WARN] Skipping trash entry %s: %v%s\n", Yellow, d.Name(), err, Reset)
+			continue
+		}
+		e.ID = d.Name()
+		e.root = root
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].RemovedAt.Before(entries[j].RemovedAt) })
+	return entries, nil
+}
+
+// currentTrashRoot is the trash used by the `ash trash` commands run from the current directory.
+func currentTrashRoot() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return findTrashRoot(wd), nil
+}
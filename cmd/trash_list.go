@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var trashListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List folders in the workspace trash",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := currentTrashRoot()
+		if err != nil {
+			return err
+		}
+		entries, err := loadTrash(root)
+		if err != nil {
+			return err
+		}
+
+		if structuredOutput() {
+			if entries == nil {
+				entries = []trashEntry{}
+			}
+			return writeStructured(entries)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("Trash is empty.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tREMOVED\tKIND\tNAME\tORIGINAL\tCOMMAND")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				e.ID, e.RemovedAt.Format("2006-01-02 15:04"), e.Kind, e.Name, e.Original, e.Command)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(trashListCmd)
+}
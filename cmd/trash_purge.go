@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	trashPurgeOlderThan string
+	trashPurgeAll       bool
+)
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete trash entries",
+	Example: `  ash trash purge --older-than 30d
+  ash trash purge --all`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !trashPurgeAll && trashPurgeOlderThan == "" {
+			return fmt.Errorf("specify --older-than <age> (e.g. 7d, 12h) or --all")
+		}
+		var maxAge time.Duration
+		if !trashPurgeAll {
+			d, err := parseAge(trashPurgeOlderThan)
+			if err != nil {
+				return err
+			}
+			maxAge = d
+		}
+
+		root, err := currentTrashRoot()
+		if err != nil {
+			return err
+		}
+		entries, err := loadTrash(root)
+		if err != nil {
+			return err
+		}
+
+		purged := 0
+		cutoff := time.Now().Add(-maxAge)
+		for _, e := range entries {
+			if !trashPurgeAll && e.RemovedAt.After(cutoff) {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(trashDir(root), e.ID)); err != nil {
+				fmt.Printf("%s[ERR] Failed to purge %s: %v%s\n", Red, e.ID, err, Reset)
+				continue
+			}
+			fmt.Printf("%s[DEL] Purged %s (%s %s)%s\n", Red, e.ID, e.Kind, e.Name, Reset)
+			purged++
+		}
+		fmt.Printf("Purged %d of %d trash entries.\n", purged, len(entries))
+		return nil
+	},
+}
+
+// parseAge parses a duration that additionally accepts days ("30d") and weeks ("2w").
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d, 2w, 12h)", s)
+	}
+	return d, nil
+}
+
+func init() {
+	trashCmd.AddCommand(trashPurgeCmd)
+	trashPurgeCmd.Flags().StringVar(&trashPurgeOlderThan, "older-than", "", "Only purge entries removed longer ago than this (e.g. 30d, 2w, 12h)")
+	trashPurgeCmd.Flags().BoolVar(&trashPurgeAll, "all", false, "Purge every entry")
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore [id]",
+	Short: "Move a trashed folder back to where it was removed from",
+	Long: `Move a trashed folder back to its original location.
+The restore is refused if something already exists there.
+Run 'ash group sync' or 'ash subgroup sync' afterwards to refresh metadata.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, err := currentTrashRoot()
+		if err != nil {
+			return err
+		}
+		entries, err := loadTrash(root)
+		if err != nil {
+			return err
+		}
+
+		var entry *trashEntry
+		for i := range entries {
+			if entries[i].ID == args[0] {
+				entry = &entries[i]
+				break
+			}
+		}
+		if entry == nil {
+			return fmt.Errorf("trash entry %q not found in %s (see 'ash trash list')", args[0], trashDir(root))
+		}
+
+		dest := entry.originalPath()
+		if fileExists(dest) {
+			return fmt.Errorf("cannot restore: %s already exists", dest)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(entry.dataPath(), dest); err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+		if err := os.RemoveAll(filepath.Join(trashDir(root), entry.ID)); err != nil {
+			fmt.Printf("%s[WARN] Restored, but failed to remove trash entry: %v%s\n", Yellow, err, Reset)
+		}
+
+		fmt.Printf("%s[OK] Restored %s %s to %s%s\n", Green, entry.Kind, entry.Name, dest, Reset)
+		return nil
+	},
+}
+
+func init() {
+	trashCmd.AddCommand(trashRestoreCmd)
+}
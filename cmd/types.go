@@ -1,62 +1,99 @@
 package cmd
 
+import "github.com/warmdev17/ash/internal/gitlab"
+
 // GitLabGroup represents a GitLab group (used across multiple subcommands)
 type GitLabGroup struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
-	Path string `json:"path"`
+	ID   int64  `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
 }
 
 // AshConfig defines ~/.config/ash/config.json structure
 type AshConfig struct {
 	Groups      []GitLabGroup `json:"groups"`
 	GitProtocol string        `json:"git_protocol"`
-}
 
-type glProject struct {
-	ID            int64  `json:"id"`
-	Path          string `json:"path"`
-	Name          string `json:"name"`
-	SSHURLToRepo  string `json:"ssh_url_to_repo"`
-	HTTPURLToRepo string `json:"http_url_to_repo"`
-}
+	// GitLab connection (written by `ash auth login`)
+	Host        string `json:"host,omitempty"`
+	APIHost     string `json:"api_host,omitempty"`
+	APIProtocol string `json:"api_protocol,omitempty"`
+	Token       string `json:"token,omitempty"`
+
+	// API retry policy; unset fields keep the defaults (see retry.go)
+	Retries       *int   `json:"retries,omitempty"`
+	RetryDelay    string `json:"retry_delay,omitempty"`
+	RetryMaxDelay string `json:"retry_max_delay,omitempty"`
 
-type glGroup struct {
-	ID                  int64  `json:"id"`
-	Name                string `json:"name"`
-	Path                string `json:"path"`
-	MarkedForDeletionOn string `json:"marked_for_deletion_on"`
+	// Max repositories cloned/pulled/submitted at once (--jobs overrides)
+	Jobs int `json:"jobs,omitempty"`
+
+	// How new local folders are named: name|path (--folder-naming overrides)
+	FolderNaming string `json:"folder_naming,omitempty"`
 }
 
+// API response types live in internal/gitlab; the aliases keep the short
+// names used throughout the commands.
+type (
+	glProject = gitlab.Project
+	glGroup   = gitlab.Group
+)
+
 // ---------- Metadata types ----------
 
 // Identifiers used in metadata files
 type groupIdent struct {
-	ID   int64  `json:"id"`
-	Path string `json:"path"`
-	Name string `json:"name"`
+	ID   int64  `json:"id" yaml:"id"`
+	Path string `json:"path" yaml:"path"`
+	Name string `json:"name" yaml:"name"`
 }
 
+// Dir is the local folder of a subgroup or project, relative to the level
+// holding it (see folders.go).
 type projectIdent struct {
-	ID   int64  `json:"id"`
-	Path string `json:"path"`
-	Name string `json:"name"`
+	ID   int64  `json:"id" yaml:"id"`
+	Path string `json:"path" yaml:"path"`
+	Name string `json:"name" yaml:"name"`
+	Dir  string `json:"dir,omitempty" yaml:"dir,omitempty"`
 }
 
 type subgroupIdent struct {
-	ID   int64  `json:"id"`
-	Path string `json:"path"`
-	Name string `json:"name"`
+	ID   int64  `json:"id" yaml:"id"`
+	Path string `json:"path" yaml:"path"`
+	Name string `json:"name" yaml:"name"`
+	Dir  string `json:"dir,omitempty" yaml:"dir,omitempty"`
 }
 
 // Root group meta: .ash/group.json
+// Older layouts are upgraded on read (see metadata.go).
+// Projects lists the projects that live directly in the root group.
+// Template seeds new projects of this level (see template.go).
 type rootGroupMeta struct {
-	Group     groupIdent      `json:"group"`
-	Subgroups []subgroupIdent `json:"subgroup"`
+	SchemaVersion int             `json:"schema_version"`
+	Group         groupIdent      `json:"group"`
+	Subgroups     []subgroupIdent `json:"subgroups"`
+	Projects      []projectIdent  `json:"projects,omitempty"`
+	Template      string          `json:"template,omitempty"`
 }
 
 // Subgroup meta: .ash/subgroup.json
+// Subgroups lists nested subgroups (any depth is allowed).
+// Template seeds new projects of this subgroup (see template.go).
 type subgroupMeta struct {
-	Group    groupIdent     `json:"group"`
-	Projects []projectIdent `json:"projects"`
+	SchemaVersion int             `json:"schema_version"`
+	Group         groupIdent      `json:"group"`
+	Projects      []projectIdent  `json:"projects"`
+	Subgroups     []subgroupIdent `json:"subgroups,omitempty"`
+	Template      string          `json:"template,omitempty"`
+}
+
+// levelMeta is one level of the hierarchy regardless of its file: the root
+// group (group.json) or a subgroup at any depth (subgroup.json). Every level
+// may hold both subgroups and projects.
+type levelMeta struct {
+	Root      bool // stored in group.json
+	Group     groupIdent
+	Subgroups []subgroupIdent
+	Projects  []projectIdent
+	Template  string
 }
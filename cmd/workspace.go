@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --- WORKSPACE DISCOVERY ---
+// Commands may run from any folder inside a checkout (e.g. Lab1/src/main).
+// The locator walks up to the nearest level (a folder with .ash metadata),
+// the nearest subgroup root (.ash/subgroup.json) and the group root
+// (.ash/group.json), and remembers which project folder, if any, the
+// starting directory lies in. Subgroups nest to any depth, so the nearest
+// level may be the group root itself or a subgroup several folders down.
+
+var (
+	errNoSubgroup = errors.New("not in a subgroup folder (.ash/subgroup.json not found in this or any parent directory)")
+	errNoGroup    = errors.New("not in a group folder (.ash/group.json not found in this or any parent directory)")
+	errNoLevel    = errors.New("not in an ash workspace (.ash/group.json or .ash/subgroup.json not found in this or any parent directory)")
+)
+
+type workspace struct {
+	Dir          string // directory the lookup started from
+	GroupRoot    string // folder holding .ash/group.json ("" if none)
+	SubgroupRoot string // nearest folder holding .ash/subgroup.json ("" if none)
+	Level        string // nearest folder holding either file ("" if none)
+	Project      string // project folder below Level containing Dir ("" if none)
+}
+
+// locateWorkspace resolves the workspace containing start.
+func locateWorkspace(start string) (*workspace, error) {
+	abs, err := filepath.Abs(start)
+	if err != nil {
+		return nil, err
+	}
+	ws := &workspace{Dir: abs}
+
+	for dir := abs; ; {
+		if ws.SubgroupRoot == "" && fileExists(filepath.Join(dir, ".ash", "subgroup.json")) {
+			ws.SubgroupRoot = dir
+		}
+		if fileExists(filepath.Join(dir, ".ash", "group.json")) {
+			ws.GroupRoot = dir
+		}
+		if ws.Level == "" && (ws.SubgroupRoot != "" || ws.GroupRoot != "") {
+			ws.Level = dir
+		}
+		if ws.GroupRoot != "" {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	if ws.Level != "" && ws.Level != abs {
+		rel, err := filepath.Rel(ws.Level, abs)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			ws.Project = strings.Split(rel, string(filepath.Separator))[0]
+		}
+	}
+	return ws, nil
+}
+
+// currentWorkspace resolves the workspace of the working directory.
+func currentWorkspace() (*workspace, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return locateWorkspace(wd)
+}
+
+// subgroupRoot returns the nearest subgroup root or errNoSubgroup.
+func (w *workspace) subgroupRoot() (string, error) {
+	if w.SubgroupRoot == "" {
+		return "", errNoSubgroup
+	}
+	return w.SubgroupRoot, nil
+}
+
+// groupRoot returns the nearest group root or errNoGroup.
+func (w *workspace) groupRoot() (string, error) {
+	if w.GroupRoot == "" {
+		return "", errNoGroup
+	}
+	return w.GroupRoot, nil
+}
+
+// levelRoot returns the nearest level (group root or subgroup) or errNoLevel.
+func (w *workspace) levelRoot() (string, error) {
+	if w.Level == "" {
+		return "", errNoLevel
+	}
+	return w.Level, nil
+}
+
+// inProject reports whether the lookup started inside a project folder.
+func (w *workspace) inProject() bool {
+	return w.Project != ""
+}
+
+// projectDir is the folder of the current project ("" if not in one).
+func (w *workspace) projectDir() string {
+	if w.Project == "" {
+		return ""
+	}
+	return filepath.Join(w.Level, w.Project)
+}
@@ -0,0 +1,65 @@
+// Package filelock provides exclusive advisory locks on lock files, used to
+// keep concurrent ash processes from interleaving read-modify-write cycles.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrTimeout is returned by Acquire when the lock stays held by someone else.
+var ErrTimeout = errors.New("timed out waiting for lock")
+
+// pollInterval is how often Acquire retries a held lock.
+const pollInterval = 50 * time.Millisecond
+
+// Lock is an exclusive lock held on an open lock file.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire takes the lock on path, creating the file (and its directory) if
+// needed, and waits up to timeout for another holder to release it.
+// Locks are per open file, so two goroutines of one process exclude each other too.
+func Acquire(path string, timeout time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w", path, err)
+		}
+		if ok {
+			return &Lock{f: f}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w", path, ErrTimeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Release unlocks and closes the lock file. The file itself is left in place.
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
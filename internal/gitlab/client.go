@@ -0,0 +1,235 @@
+// Package gitlab is a small in-process client for the subset of the GitLab
+// REST API (v4) that ash needs. It replaces the former `glab api` shell-outs.
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a single HTTP round trip when the caller does not
+// supply its own http.Client.
+const DefaultTimeout = 60 * time.Second
+
+// API is the set of GitLab operations used by the ash commands.
+// *Client implements it; commands depend on the interface so that a stand-in
+// (e.g. backed by httptest.Server) can be injected.
+type API interface {
+	CurrentUser(ctx context.Context) (*User, error)
+	GetApplicationSettings(ctx context.Context) (*ApplicationSettings, error)
+
+	ListGroups(ctx context.Context, opt ListGroupsOptions) ([]Group, error)
+	GetGroup(ctx context.Context, id int64) (*Group, error)
+	GetGroupByPath(ctx context.Context, fullPath string) (*Group, error)
+	CreateGroup(ctx context.Context, opt CreateGroupOptions) (*Group, error)
+	UpdateGroup(ctx context.Context, id int64, opt UpdateGroupOptions) (*Group, error)
+	DeleteGroup(ctx context.Context, id int64) error
+	ListSubgroups(ctx context.Context, groupID int64) ([]Group, error)
+
+	ListGroupProjects(ctx context.Context, groupID int64) ([]Project, error)
+	GetProject(ctx context.Context, id int64) (*Project, error)
+	GetProjectByPath(ctx context.Context, fullPath string) (*Project, error)
+	CreateProject(ctx context.Context, opt CreateProjectOptions) (*Project, error)
+	ForkProject(ctx context.Context, id int64, opt ForkProjectOptions) (*Project, error)
+	UpdateProject(ctx context.Context, id int64, opt UpdateProjectOptions) (*Project, error)
+	DeleteProject(ctx context.Context, id int64) error
+	ListRepositoryTree(ctx context.Context, projectID int64) ([]TreeNode, error)
+}
+
+// Client talks to a single GitLab instance using a personal access token.
+type Client struct {
+	baseURL *url.URL
+	token   string
+
+	// HTTPClient is used for every request. It defaults to a client with
+	// DefaultTimeout and may be replaced before the first call.
+	HTTPClient *http.Client
+
+	// UserAgent is sent with every request when non-empty.
+	UserAgent string
+
+	// Retry is the retry policy for transient failures. NewClient sets
+	// DefaultRetryPolicy; the zero value disables retrying.
+	Retry RetryPolicy
+}
+
+var _ API = (*Client)(nil)
+
+// NewClient returns a client for the GitLab instance at baseURL.
+// baseURL may be the instance root ("https://gitlab.example.com") or the API
+// root ("https://gitlab.example.com/api/v4").
+func NewClient(baseURL, token string) (*Client, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		return nil, fmt.Errorf("gitlab: empty base URL")
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: invalid base URL %q: %w", baseURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("gitlab: base URL %q must include scheme and host", baseURL)
+	}
+	if !strings.HasSuffix(u.Path, "/api/v4") {
+		u.Path += "/api/v4"
+	}
+	u.Path += "/"
+
+	return &Client{
+		baseURL:    u,
+		token:      token,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		Retry:      DefaultRetryPolicy,
+	}, nil
+}
+
+// BaseURL returns the API root the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL.String()
+}
+
+// newRequest builds a request for the API path p (relative to /api/v4).
+// A non-nil body is sent as JSON.
+func (c *Client) newRequest(ctx context.Context, method, p string, query url.Values, body any) (*http.Request, error) {
+	rel, err := url.Parse(strings.TrimLeft(p, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: invalid path %q: %w", p, err)
+	}
+	u := c.baseURL.ResolveReference(rel)
+	if len(query) > 0 {
+		q := u.Query()
+		for k, vs := range query {
+			for _, v := range vs {
+				q.Add(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return c.newRequestURL(ctx, method, u.String(), body)
+}
+
+// newRequestURL builds a request for an absolute URL, e.g. a pagination
+// link returned by GitLab. A non-nil body is sent as JSON.
+func (c *Client) newRequestURL(ctx context.Context, method, u string, body any) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: encode request body: %w", err)
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	return req, nil
+}
+
+// do sends req and decodes a successful JSON response into v (if non-nil).
+// Transient failures are retried according to c.Retry. Non-2xx responses
+// are returned as *Error.
+func (c *Client) do(req *http.Request, v any) (*http.Response, error) {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	ctx := req.Context()
+	gate := gateFor(req.URL.Host)
+
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		if err := gate.wait(ctx, c.Retry.MaxDelay); err != nil {
+			return nil, fmt.Errorf("gitlab: %s %s: %w", req.Method, req.URL.Path, err)
+		}
+		r, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = hc.Do(r)
+		if resp != nil {
+			if reset, ok := rateLimitReset(resp.Header); ok {
+				gate.hold(reset)
+			}
+		}
+		if attempt < c.Retry.MaxRetries && retryable(req.Method, resp, err) {
+			wait := c.Retry.backoff(attempt + 1)
+			if resp != nil {
+				if d, ok := serverDelay(resp.Header, time.Now()); ok {
+					// Spread out clients that were told the same time.
+					wait = d + c.Retry.backoff(1)/2
+				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			if c.Retry.MaxDelay > 0 && wait > c.Retry.MaxDelay {
+				wait = c.Retry.MaxDelay
+			}
+			if err := sleep(ctx, wait); err != nil {
+				return nil, fmt.Errorf("gitlab: %s %s: %w", req.Method, req.URL.Path, err)
+			}
+			countRetry(ctx)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gitlab: %s %s: %w", req.Method, req.URL.Path, err)
+		}
+		break
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, newError(req, resp)
+	}
+
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return resp, fmt.Errorf("gitlab: %s %s: decode response: %w", req.Method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+// rewind returns req for the first attempt and a copy with a fresh body for
+// later ones.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+	r := req.Clone(req.Context())
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: %s %s: rewind body: %w", req.Method, req.URL.Path, err)
+	}
+	r.Body = body
+	return r, nil
+}
+
+// get is a convenience wrapper for GET requests with a JSON response.
+func (c *Client) get(ctx context.Context, p string, query url.Values, v any) error {
+	req, err := c.newRequest(ctx, http.MethodGet, p, query, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req, v)
+	return err
+}
@@ -0,0 +1,236 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient returns a client for srv without retries, so error
+// responses come back at once.
+func newTestClient(t *testing.T, srv *httptest.Server) *Client {
+	t.Helper()
+	c, err := NewClient(srv.URL, "secret-token")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	c.Retry = RetryPolicy{}
+	return c
+}
+
+func TestNewClientBaseURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://gitlab.example.com", "https://gitlab.example.com/api/v4/"},
+		{"https://gitlab.example.com/", "https://gitlab.example.com/api/v4/"},
+		{"https://gitlab.example.com/api/v4", "https://gitlab.example.com/api/v4/"},
+		{"https://gitlab.example.com/api/v4/", "https://gitlab.example.com/api/v4/"},
+		{"https://example.com/gitlab", "https://example.com/gitlab/api/v4/"},
+		{"http://127.0.0.1:8080", "http://127.0.0.1:8080/api/v4/"},
+	}
+	for _, tt := range tests {
+		c, err := NewClient(tt.in, "t")
+		if err != nil {
+			t.Errorf("NewClient(%q): %v", tt.in, err)
+			continue
+		}
+		if got := c.BaseURL(); got != tt.want {
+			t.Errorf("NewClient(%q).BaseURL() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "  ", "gitlab.example.com", "/api/v4"} {
+		if _, err := NewClient(in, "t"); err == nil {
+			t.Errorf("NewClient(%q): want an error", in)
+		}
+	}
+}
+
+func TestRequestPathAndToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret-token" {
+			t.Errorf("PRIVATE-TOKEN = %q, want %q", got, "secret-token")
+		}
+		if got := r.Header.Get("Accept"); got != "application/json" {
+			t.Errorf("Accept = %q, want application/json", got)
+		}
+		if r.URL.Path != "/gitlab/api/v4/user" {
+			t.Errorf("path = %q, want /gitlab/api/v4/user", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"id": 7, "username": "student", "name": "A Student"}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL+"/gitlab", "secret-token")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	u, err := c.CurrentUser(context.Background())
+	if err != nil {
+		t.Fatalf("CurrentUser: %v", err)
+	}
+	if u.ID != 7 || u.Username != "student" || u.Name != "A Student" {
+		t.Errorf("CurrentUser = %+v", u)
+	}
+}
+
+func TestNoTokenHeaderWithoutToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header["Private-Token"]; ok {
+			t.Error("PRIVATE-TOKEN sent without a token")
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL, "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := c.CurrentUser(context.Background()); err != nil {
+		t.Fatalf("CurrentUser: %v", err)
+	}
+}
+
+func TestCreateFromTemplateBodies(t *testing.T) {
+	var got map[string]any
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		got = nil
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 200, "path": "lab1", "import_status": "scheduled"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	if _, err := c.CreateProject(ctx, CreateProjectOptions{Name: "Lab1", Path: "lab1", NamespaceID: 12, UseCustomTemplate: true, TemplateProjectID: 70, GroupWithProjectTemplatesID: 50}); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if path != "POST /api/v4/projects" || got["use_custom_template"] != true || got["template_project_id"] != 70.0 || got["group_with_project_templates_id"] != 50.0 {
+		t.Errorf("group template: %s %v", path, got)
+	}
+
+	if _, err := c.CreateProject(ctx, CreateProjectOptions{Name: "Lab1", Path: "lab1", NamespaceID: 12, UseCustomTemplate: true, TemplateProjectID: 70}); err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if _, ok := got["group_with_project_templates_id"]; ok {
+		t.Errorf("instance template sent group_with_project_templates_id: %v", got)
+	}
+
+	p, err := c.ForkProject(ctx, 70, ForkProjectOptions{NamespaceID: 12, Name: "Lab1", Path: "lab1"})
+	if err != nil {
+		t.Fatalf("ForkProject: %v", err)
+	}
+	if path != "POST /api/v4/projects/70/fork" || got["namespace_id"] != 12.0 || got["name"] != "Lab1" || got["path"] != "lab1" {
+		t.Errorf("fork: %s %v", path, got)
+	}
+	if p.ID != 200 || p.ImportStatus != "scheduled" {
+		t.Errorf("fork returned %+v", p)
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantMessage  string
+		notFound     bool
+		unauthorized bool
+	}{
+		{
+			name:        "message string repeating the status",
+			status:      http.StatusNotFound,
+			body:        `{"message": "404 Group Not Found"}`,
+			wantMessage: "Group Not Found",
+			notFound:    true,
+		},
+		{
+			name:     "message equal to the status text",
+			status:   http.StatusNotFound,
+			body:     `{"message": "404 Not Found"}`,
+			notFound: true,
+		},
+		{
+			name:        "message with field errors",
+			status:      http.StatusBadRequest,
+			body:        `{"message": {"path": ["has already been taken"], "name": ["is too long", "is invalid"]}}`,
+			wantMessage: "name is too long; is invalid; path has already been taken",
+		},
+		{
+			name:        "message list",
+			status:      http.StatusUnprocessableEntity,
+			body:        `{"message": ["first", "second"]}`,
+			wantMessage: "first; second",
+		},
+		{
+			name:         "error with a description",
+			status:       http.StatusUnauthorized,
+			body:         `{"error": "invalid_token", "error_description": "Token was revoked."}`,
+			wantMessage:  "Token was revoked.",
+			unauthorized: true,
+		},
+		{
+			name:        "error only",
+			status:      http.StatusForbidden,
+			body:        `{"error": "insufficient_scope"}`,
+			wantMessage: "insufficient_scope",
+		},
+		{
+			name:        "plain text body",
+			status:      http.StatusForbidden,
+			body:        "Forbidden by policy",
+			wantMessage: "Forbidden by policy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			_, err := newTestClient(t, srv).GetGroup(context.Background(), 42)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("err = %v (%T), want *Error", err, err)
+			}
+			if e.StatusCode != tt.status || e.Method != http.MethodGet || e.Path != "/api/v4/groups/42" {
+				t.Errorf("Error = %+v", e)
+			}
+			if e.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", e.Message, tt.wantMessage)
+			}
+			if got := IsNotFound(err); got != tt.notFound {
+				t.Errorf("IsNotFound = %v, want %v", got, tt.notFound)
+			}
+			if got := IsUnauthorized(err); got != tt.unauthorized {
+				t.Errorf("IsUnauthorized = %v, want %v", got, tt.unauthorized)
+			}
+		})
+	}
+}
+
+func TestIsNotFoundWrapped(t *testing.T) {
+	notFound := &Error{Method: http.MethodGet, Path: "/api/v4/projects/1", StatusCode: http.StatusNotFound}
+	if !IsNotFound(fmt.Errorf("look up project: %w", notFound)) {
+		t.Error("IsNotFound(wrapped 404) = false")
+	}
+	if IsNotFound(&Error{StatusCode: http.StatusForbidden}) {
+		t.Error("IsNotFound(403) = true")
+	}
+	if IsNotFound(errors.New("404")) || IsNotFound(nil) {
+		t.Error("IsNotFound of a non-GitLab error = true")
+	}
+}
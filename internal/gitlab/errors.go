@@ -0,0 +1,109 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error is returned for any non-2xx response from GitLab.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Message is the human-readable message GitLab returned, if any.
+	Message string
+}
+
+func (e *Error) Error() string {
+	status := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message == "" {
+		return fmt.Sprintf("gitlab: %s %s: %s", e.Method, e.Path, status)
+	}
+	return fmt.Sprintf("gitlab: %s %s: %s: %s", e.Method, e.Path, status, e.Message)
+}
+
+// IsNotFound reports whether err is a GitLab 404 response.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is a GitLab 401 response.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func hasStatus(err error, code int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == code
+}
+
+func newError(req *http.Request, resp *http.Response) *Error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	msg := parseErrorMessage(body)
+	// GitLab often repeats the status ("404 Group Not Found"); drop the prefix.
+	msg = strings.TrimSpace(strings.TrimPrefix(msg, strconv.Itoa(resp.StatusCode)))
+	if strings.EqualFold(msg, http.StatusText(resp.StatusCode)) {
+		msg = ""
+	}
+	return &Error{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Message:    msg,
+	}
+}
+
+// parseErrorMessage extracts the message from a GitLab error body.
+// GitLab uses several shapes:
+//
+//	{"message": "404 Group Not Found"}
+//	{"message": {"path": ["has already been taken"]}}
+//	{"error": "invalid_token", "error_description": "..."}
+func parseErrorMessage(body []byte) string {
+	var raw struct {
+		Message          json.RawMessage `json:"message"`
+		Error            string          `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	if len(raw.Message) > 0 {
+		return flattenMessage(raw.Message)
+	}
+	if raw.ErrorDescription != "" {
+		return raw.ErrorDescription
+	}
+	return raw.Error
+}
+
+func flattenMessage(m json.RawMessage) string {
+	var s string
+	if json.Unmarshal(m, &s) == nil {
+		return s
+	}
+	var list []string
+	if json.Unmarshal(m, &list) == nil {
+		return strings.Join(list, "; ")
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(m, &fields) == nil {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+" "+flattenMessage(fields[k]))
+		}
+		return strings.Join(parts, "; ")
+	}
+	return string(m)
+}
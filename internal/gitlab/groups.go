@@ -0,0 +1,122 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Group is a GitLab group or subgroup.
+type Group struct {
+	ID                  int64  `json:"id"`
+	Name                string `json:"name"`
+	Path                string `json:"path"`
+	FullPath            string `json:"full_path,omitempty"`
+	ParentID            int64  `json:"parent_id,omitempty"`
+	Visibility          string `json:"visibility,omitempty"`
+	Description         string `json:"description,omitempty"`
+	MarkedForDeletionOn string `json:"marked_for_deletion_on,omitempty"`
+
+	// The subgroup whose projects are offered as custom project templates
+	// inside this group (paid GitLab feature; 0 when unset or unavailable).
+	CustomProjectTemplatesGroupID int64 `json:"custom_project_templates_group_id,omitempty"`
+}
+
+// ListGroupsOptions filters GET /groups.
+type ListGroupsOptions struct {
+	Owned        bool
+	TopLevelOnly bool
+}
+
+// CreateGroupOptions is the body of POST /groups.
+// A non-zero ParentID creates a subgroup.
+type CreateGroupOptions struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ParentID    int64  `json:"parent_id,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateGroupOptions is the body of PUT /groups/:id. Empty fields are left unchanged.
+type UpdateGroupOptions struct {
+	Name        string `json:"name,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ListGroups returns all groups visible to the authenticated user.
+func (c *Client) ListGroups(ctx context.Context, opt ListGroupsOptions) ([]Group, error) {
+	q := url.Values{}
+	if opt.Owned {
+		q.Set("owned", "true")
+	}
+	if opt.TopLevelOnly {
+		q.Set("top_level_only", "true")
+	}
+	return listAll[Group](ctx, c, "groups", q)
+}
+
+// GetGroup returns the group with the given ID.
+func (c *Client) GetGroup(ctx context.Context, id int64) (*Group, error) {
+	var g Group
+	if err := c.get(ctx, "groups/"+strconv.FormatInt(id, 10), nil, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// GetGroupByPath returns the group with the given full path (e.g. "course/session-1").
+func (c *Client) GetGroupByPath(ctx context.Context, fullPath string) (*Group, error) {
+	var g Group
+	if err := c.get(ctx, "groups/"+url.PathEscape(fullPath), nil, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// CreateGroup creates a group (or a subgroup when opt.ParentID is set).
+func (c *Client) CreateGroup(ctx context.Context, opt CreateGroupOptions) (*Group, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "groups", nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	var g Group
+	if _, err := c.do(req, &g); err != nil {
+		return nil, err
+	}
+	if g.ID == 0 || g.Path == "" {
+		return nil, fmt.Errorf("gitlab: POST /groups: unexpected response: missing id/path")
+	}
+	return &g, nil
+}
+
+// UpdateGroup changes the settings of a group.
+func (c *Client) UpdateGroup(ctx context.Context, id int64, opt UpdateGroupOptions) (*Group, error) {
+	req, err := c.newRequest(ctx, http.MethodPut, "groups/"+strconv.FormatInt(id, 10), nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	var g Group
+	if _, err := c.do(req, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// DeleteGroup deletes (or schedules deletion of) a group.
+func (c *Client) DeleteGroup(ctx context.Context, id int64) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, "groups/"+strconv.FormatInt(id, 10), nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req, nil)
+	return err
+}
+
+// ListSubgroups returns all direct subgroups of a group.
+func (c *Client) ListSubgroups(ctx context.Context, groupID int64) ([]Group, error) {
+	return listAll[Group](ctx, c, fmt.Sprintf("groups/%d/subgroups", groupID), nil)
+}
@@ -0,0 +1,93 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageSize is the per_page value used for list endpoints (GitLab's maximum).
+const PageSize = 100
+
+// maxPages guards against a server that keeps pointing at the same page.
+const maxPages = 10000
+
+// listAll GETs every page of the list endpoint p and concatenates the results.
+//
+// GitLab advertises the next page through the X-Next-Page header (offset
+// pagination) and/or a Link header with rel="next" (offset and keyset
+// pagination). X-Next-Page is preferred; the Link URL is followed verbatim
+// otherwise. A response with neither ends the listing.
+func listAll[T any](ctx context.Context, c *Client, p string, query url.Values) ([]T, error) {
+	q := url.Values{}
+	for k, vs := range query {
+		q[k] = append([]string(nil), vs...)
+	}
+	q.Set("per_page", strconv.Itoa(PageSize))
+	q.Set("page", "1")
+
+	all := make([]T, 0)
+	nextURL := ""
+	seen := map[string]bool{}
+
+	for i := 0; i < maxPages; i++ {
+		var req *http.Request
+		var err error
+		if nextURL != "" {
+			req, err = c.newRequestURL(ctx, http.MethodGet, nextURL, nil)
+		} else {
+			req, err = c.newRequest(ctx, http.MethodGet, p, q, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if seen[req.URL.String()] {
+			return nil, fmt.Errorf("gitlab: GET %s: pagination loop at %s", req.URL.Path, req.URL.RawQuery)
+		}
+		seen[req.URL.String()] = true
+
+		var page []T
+		resp, err := c.do(req, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+
+		if np := strings.TrimSpace(resp.Header.Get("X-Next-Page")); np != "" {
+			q.Set("page", np)
+			nextURL = ""
+			continue
+		}
+		if link := nextLink(resp.Header.Get("Link")); link != "" {
+			nextURL = link
+			continue
+		}
+		return all, nil
+	}
+	return nil, fmt.Errorf("gitlab: GET %s: more than %d pages", p, maxPages)
+}
+
+// nextLink returns the URL of the rel="next" entry of an RFC 8288 Link
+// header, or "" if there is none.
+func nextLink(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		segs := strings.Split(part, ";")
+		if len(segs) < 2 {
+			continue
+		}
+		target := strings.TrimSpace(segs[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range segs[1:] {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(k, "rel") && strings.Trim(v, `"`) == "next" {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// pagedServer serves total groups PageSize at a time from
+// /api/v4/groups, announcing the next page the way header says:
+// "x-next-page", "link" or "both". It counts the requests it gets.
+func pagedServer(t *testing.T, total int, header string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/api/v4/groups" {
+			t.Errorf("path = %q, want /api/v4/groups", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("per_page"); got != strconv.Itoa(PageSize) {
+			t.Errorf("per_page = %q, want %d", got, PageSize)
+		}
+		if got := r.URL.Query().Get("owned"); got != "true" {
+			t.Errorf("owned = %q, want the query kept on every page", got)
+		}
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			t.Errorf("page = %q", r.URL.Query().Get("page"))
+			page = 1
+		}
+
+		from := min((page-1)*PageSize, total)
+		to := min(from+PageSize, total)
+		if to < total {
+			next := strconv.Itoa(page + 1)
+			if header == "x-next-page" || header == "both" {
+				w.Header().Set("X-Next-Page", next)
+			}
+			if header == "link" || header == "both" {
+				link := fmt.Sprintf("%s/api/v4/groups?owned=true&page=%s&per_page=%d", srv.URL, next, PageSize)
+				w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/groups?page=1>; rel="first", <%s>; rel="next"`, srv.URL, link))
+			}
+		} else if header == "x-next-page" || header == "both" {
+			w.Header().Set("X-Next-Page", "") // GitLab sends it empty on the last page
+		}
+
+		w.Write([]byte("["))
+		for i := from; i < to; i++ {
+			if i > from {
+				w.Write([]byte(","))
+			}
+			fmt.Fprintf(w, `{"id": %d, "name": "g%d", "path": "g%d"}`, i+1, i+1, i+1)
+		}
+		w.Write([]byte("]"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestListAllPages(t *testing.T) {
+	sizes := []struct {
+		items, requests int
+	}{
+		{0, 1},
+		{1, 1},
+		{100, 1},
+		{101, 2},
+		{1000, 10},
+	}
+	for _, header := range []string{"x-next-page", "link", "both"} {
+		for _, sz := range sizes {
+			t.Run(fmt.Sprintf("%s/%d", header, sz.items), func(t *testing.T) {
+				srv, requests := pagedServer(t, sz.items, header)
+				groups, err := newTestClient(t, srv).ListGroups(context.Background(), ListGroupsOptions{Owned: true})
+				if err != nil {
+					t.Fatalf("ListGroups: %v", err)
+				}
+				if len(groups) != sz.items {
+					t.Errorf("got %d groups, want %d", len(groups), sz.items)
+				}
+				for i, g := range groups {
+					if g.ID != int64(i+1) {
+						t.Fatalf("groups[%d].ID = %d, want %d", i, g.ID, i+1)
+					}
+				}
+				if got := int(requests.Load()); got != sz.requests {
+					t.Errorf("made %d requests, want %d", got, sz.requests)
+				}
+			})
+		}
+	}
+}
+
+func TestListAllEmptyListIsNotNil(t *testing.T) {
+	srv, _ := pagedServer(t, 0, "x-next-page")
+	groups, err := newTestClient(t, srv).ListGroups(context.Background(), ListGroupsOptions{Owned: true})
+	if err != nil {
+		t.Fatalf("ListGroups: %v", err)
+	}
+	if groups == nil {
+		t.Error("ListGroups returned nil for an empty list")
+	}
+}
+
+func TestListAllStopsOnPaginationLoop(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("X-Next-Page", "1") // always the first page again
+		w.Write([]byte(`[{"id": 1}]`))
+	}))
+	defer srv.Close()
+
+	if _, err := newTestClient(t, srv).ListGroups(context.Background(), ListGroupsOptions{}); err == nil {
+		t.Fatal("ListGroups: want a pagination loop error")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", ""},
+		{`<https://h/api/v4/groups?page=2>; rel="next"`, "https://h/api/v4/groups?page=2"},
+		{`<https://h/a?page=1>; rel="first", <https://h/a?page=3>; rel="next", <https://h/a?page=9>; rel="last"`, "https://h/a?page=3"},
+		{`<https://h/a?cursor=xyz>; rel=next`, "https://h/a?cursor=xyz"},
+		{`<https://h/a?page=1>; rel="prev"`, ""},
+		{`https://h/a?page=2; rel="next"`, ""},
+	}
+	for _, tt := range tests {
+		if got := nextLink(tt.header); got != tt.want {
+			t.Errorf("nextLink(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Project is a GitLab project (repository).
+type Project struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	Path              string `json:"path"`
+	PathWithNamespace string `json:"path_with_namespace,omitempty"`
+	Description       string `json:"description,omitempty"`
+	Visibility        string `json:"visibility,omitempty"`
+	DefaultBranch     string `json:"default_branch,omitempty"`
+	EmptyRepo         bool   `json:"empty_repo,omitempty"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+
+	Namespace Namespace `json:"namespace"`
+
+	// Set while a project created from an import URL or template is being
+	// filled: scheduled, started, finished or failed ("none" otherwise).
+	ImportStatus string `json:"import_status,omitempty"`
+	ImportError  string `json:"import_error,omitempty"`
+}
+
+// Namespace is the group (or user) a project lives in.
+type Namespace struct {
+	ID       int64  `json:"id"`
+	Kind     string `json:"kind,omitempty"` // "group" or "user"
+	FullPath string `json:"full_path,omitempty"`
+}
+
+// CreateProjectOptions is the body of POST /projects.
+type CreateProjectOptions struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	NamespaceID int64  `json:"namespace_id"`
+	Visibility  string `json:"visibility,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// The repository is born with content: imported from ImportURL, or
+	// copied from the custom project template TemplateProjectID
+	// (UseCustomTemplate must be set). Group-level templates also need
+	// GroupWithProjectTemplatesID, the group holding them; instance-level
+	// ones leave it empty. Custom templates are a paid GitLab feature.
+	ImportURL                   string `json:"import_url,omitempty"`
+	UseCustomTemplate           bool   `json:"use_custom_template,omitempty"`
+	TemplateProjectID           int64  `json:"template_project_id,omitempty"`
+	GroupWithProjectTemplatesID int64  `json:"group_with_project_templates_id,omitempty"`
+}
+
+// ForkProjectOptions is the body of POST /projects/:id/fork.
+type ForkProjectOptions struct {
+	NamespaceID int64  `json:"namespace_id"`
+	Name        string `json:"name,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+}
+
+// UpdateProjectOptions is the body of PUT /projects/:id. Empty fields are left unchanged.
+type UpdateProjectOptions struct {
+	Name          string `json:"name,omitempty"`
+	Visibility    string `json:"visibility,omitempty"`
+	Description   string `json:"description,omitempty"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+}
+
+// TreeNode is one entry of a repository tree listing.
+type TreeNode struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// ListGroupProjects returns all projects directly inside a group.
+func (c *Client) ListGroupProjects(ctx context.Context, groupID int64) ([]Project, error) {
+	q := url.Values{"simple": {"true"}}
+	return listAll[Project](ctx, c, fmt.Sprintf("groups/%d/projects", groupID), q)
+}
+
+// GetProject returns the project with the given ID, with all its settings
+// (ListGroupProjects uses the simple view, which lacks e.g. visibility).
+func (c *Client) GetProject(ctx context.Context, id int64) (*Project, error) {
+	var p Project
+	if err := c.get(ctx, "projects/"+strconv.FormatInt(id, 10), nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetProjectByPath returns the project with the given full path (e.g. "course/templates/lab").
+func (c *Client) GetProjectByPath(ctx context.Context, fullPath string) (*Project, error) {
+	var p Project
+	if err := c.get(ctx, "projects/"+url.PathEscape(fullPath), nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// CreateProject creates a project in the namespace given by opt.NamespaceID.
+func (c *Client) CreateProject(ctx context.Context, opt CreateProjectOptions) (*Project, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "projects", nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	var p Project
+	if _, err := c.do(req, &p); err != nil {
+		return nil, err
+	}
+	if p.ID == 0 {
+		return nil, fmt.Errorf("gitlab: POST /projects: unexpected response: missing id")
+	}
+	return &p, nil
+}
+
+// ForkProject copies the project with the given ID, history included, into
+// the namespace given by opt.NamespaceID. Like an import, the copy is filled
+// asynchronously (see Project.ImportStatus).
+func (c *Client) ForkProject(ctx context.Context, id int64, opt ForkProjectOptions) (*Project, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, fmt.Sprintf("projects/%d/fork", id), nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	var p Project
+	if _, err := c.do(req, &p); err != nil {
+		return nil, err
+	}
+	if p.ID == 0 {
+		return nil, fmt.Errorf("gitlab: POST /projects/%d/fork: unexpected response: missing id", id)
+	}
+	return &p, nil
+}
+
+// UpdateProject changes the settings of a project.
+func (c *Client) UpdateProject(ctx context.Context, id int64, opt UpdateProjectOptions) (*Project, error) {
+	req, err := c.newRequest(ctx, http.MethodPut, "projects/"+strconv.FormatInt(id, 10), nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	var p Project
+	if _, err := c.do(req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeleteProject deletes (or schedules deletion of) a project.
+func (c *Client) DeleteProject(ctx context.Context, id int64) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, "projects/"+strconv.FormatInt(id, 10), nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req, nil)
+	return err
+}
+
+// ListRepositoryTree returns the top-level entries of a project's default
+// branch. An empty repository yields a 404 error (see IsNotFound).
+func (c *Client) ListRepositoryTree(ctx context.Context, projectID int64) ([]TreeNode, error) {
+	var nodes []TreeNode
+	p := fmt.Sprintf("projects/%d/repository/tree", projectID)
+	if err := c.get(ctx, p, nil, &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
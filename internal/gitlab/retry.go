@@ -0,0 +1,191 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy controls how requests that failed transiently are retried.
+//
+// Retried are 429 Too Many Requests and 503 Service Unavailable for every
+// method, 500/502/504 for idempotent ones (a POST behind a failing proxy may
+// already have taken effect) and network errors for GET requests. Between attempts the client waits for the
+// delay GitLab asked for (Retry-After, RateLimit-Reset) or, without a hint,
+// an exponential backoff with jitter: BaseDelay, 2*BaseDelay, 4*BaseDelay...
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; 0 disables retrying.
+	MaxRetries int
+	// BaseDelay is the first backoff step.
+	BaseDelay time.Duration
+	// MaxDelay caps a single wait, including server-provided hints.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used by NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 4,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+// backoff returns the wait before retry number n (starting at 1): the
+// exponential step with "equal jitter", i.e. uniformly in [step/2, step].
+func (p RetryPolicy) backoff(n int) time.Duration {
+	step := p.BaseDelay
+	for i := 1; i < n && step < p.MaxDelay; i++ {
+		step *= 2
+	}
+	if p.MaxDelay > 0 && step > p.MaxDelay {
+		step = p.MaxDelay
+	}
+	if step <= 0 {
+		return 0
+	}
+	return step/2 + rand.N(step/2+1)
+}
+
+// retryable reports whether a request with the given method may be retried
+// after resp/err.
+func retryable(method string, resp *http.Response, err error) bool {
+	if resp == nil {
+		// Transport error. The request may have reached the server.
+		return err != nil && method == http.MethodGet && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true // refused before being handled
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+
+// idempotent reports whether sending a request twice has the effect of
+// sending it once. DELETE is left out: repeating one that went through
+// fails with 404.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut:
+		return true
+	}
+	return false
+}
+
+// serverDelay returns the wait GitLab asked for, if any: Retry-After
+// (seconds or HTTP date), else RateLimit-Reset when RateLimit-Remaining is 0.
+func serverDelay(h http.Header, now time.Time) (time.Duration, bool) {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+	}
+	if reset, ok := rateLimitReset(h); ok {
+		return max(reset.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// rateLimitReset returns the time the rate limit window resets when the
+// response says the remaining budget is exhausted.
+func rateLimitReset(h http.Header) (time.Time, bool) {
+	if strings.TrimSpace(h.Get("RateLimit-Remaining")) != "0" {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(strings.TrimSpace(h.Get("RateLimit-Reset")), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0), true
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// --- RATE LIMIT GATE ---
+// When a response reports RateLimit-Remaining: 0, further requests to the
+// same host are held until RateLimit-Reset. The gate is shared by every
+// Client for that host, since the commands create clients freely.
+
+var gates sync.Map // host -> *rateGate
+
+type rateGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func gateFor(host string) *rateGate {
+	g, _ := gates.LoadOrStore(host, &rateGate{})
+	return g.(*rateGate)
+}
+
+// hold pauses requests until t (never shortens an existing pause).
+func (g *rateGate) hold(t time.Time) {
+	g.mu.Lock()
+	if t.After(g.until) {
+		g.until = t
+	}
+	g.mu.Unlock()
+}
+
+// wait blocks until the gate is open, at most max.
+func (g *rateGate) wait(ctx context.Context, max time.Duration) error {
+	g.mu.Lock()
+	d := time.Until(g.until)
+	g.mu.Unlock()
+	if max > 0 && d > max {
+		d = max
+	}
+	return sleep(ctx, d)
+}
+
+// --- RETRY STATS ---
+
+// RetryStats counts the retries made by requests sent with a context from
+// WithRetryStats, so callers can report them.
+type RetryStats struct {
+	n atomic.Int64
+}
+
+type retryStatsKey struct{}
+
+// WithRetryStats returns a context that records retries into the returned stats.
+func WithRetryStats(ctx context.Context) (context.Context, *RetryStats) {
+	s := &RetryStats{}
+	return context.WithValue(ctx, retryStatsKey{}, s), s
+}
+
+// Retries returns the number of retries recorded so far.
+func (s *RetryStats) Retries() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.n.Load())
+}
+
+func countRetry(ctx context.Context) {
+	if s, ok := ctx.Value(retryStatsKey{}).(*RetryStats); ok {
+		s.n.Add(1)
+	}
+}
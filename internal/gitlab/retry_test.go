@@ -0,0 +1,77 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetriedStatuses(t *testing.T) {
+	calls := map[string]func(c *Client) error{
+		http.MethodGet: func(c *Client) error {
+			_, err := c.GetProject(context.Background(), 1)
+			return err
+		},
+		http.MethodPost: func(c *Client) error {
+			_, err := c.CreateProject(context.Background(), CreateProjectOptions{Name: "Lab1", Path: "lab1", NamespaceID: 2})
+			return err
+		},
+		http.MethodPut: func(c *Client) error {
+			_, err := c.UpdateProject(context.Background(), 1, UpdateProjectOptions{Name: "Lab 1"})
+			return err
+		},
+		http.MethodDelete: func(c *Client) error {
+			return c.DeleteProject(context.Background(), 1)
+		},
+	}
+	tests := []struct {
+		status  int
+		method  string
+		retried bool
+	}{
+		{http.StatusTooManyRequests, http.MethodPost, true},
+		{http.StatusServiceUnavailable, http.MethodPost, true},
+		{http.StatusServiceUnavailable, http.MethodDelete, true},
+		{http.StatusBadGateway, http.MethodPost, false},
+		{http.StatusGatewayTimeout, http.MethodPost, false},
+		{http.StatusInternalServerError, http.MethodPost, false},
+		{http.StatusBadGateway, http.MethodDelete, false},
+		{http.StatusBadGateway, http.MethodGet, true},
+		{http.StatusGatewayTimeout, http.MethodGet, true},
+		{http.StatusInternalServerError, http.MethodGet, true},
+		{http.StatusGatewayTimeout, http.MethodPut, true},
+		{http.StatusNotFound, http.MethodGet, false},
+		{http.StatusConflict, http.MethodPost, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d/%s", tt.status, tt.method), func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != tt.method {
+					t.Errorf("method = %s, want %s", r.Method, tt.method)
+				}
+				if requests.Add(1) == 1 {
+					w.WriteHeader(tt.status)
+					w.Write([]byte(`{"message": "failing"}`))
+					return
+				}
+				w.Write([]byte(`{"id": 1, "name": "Lab 1", "path": "lab1"}`))
+			}))
+			defer srv.Close()
+			c := newTestClient(t, srv)
+			c.Retry = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+			err := calls[tt.method](c)
+			if got := requests.Load() == 2; got != tt.retried {
+				t.Errorf("retried = %v (%d requests), want %v", got, requests.Load(), tt.retried)
+			}
+			if (err == nil) != tt.retried {
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
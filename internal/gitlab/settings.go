@@ -0,0 +1,20 @@
+package gitlab
+
+import "context"
+
+// ApplicationSettings is the part of the instance settings ash reads.
+type ApplicationSettings struct {
+	// The group whose projects are the instance-level custom project
+	// templates (paid GitLab feature; 0 when unset or unavailable).
+	CustomProjectTemplatesGroupID int64 `json:"custom_project_templates_group_id,omitempty"`
+}
+
+// GetApplicationSettings returns the instance settings. Only administrators
+// may read them; other users get a 403 error.
+func (c *Client) GetApplicationSettings(ctx context.Context) (*ApplicationSettings, error) {
+	var s ApplicationSettings
+	if err := c.get(ctx, "application/settings", nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,20 @@
+package gitlab
+
+import "context"
+
+// User is the authenticated GitLab user.
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// CurrentUser returns the user owning the client's token.
+// It is a cheap way to validate a token.
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var u User
+	if err := c.get(ctx, "user", nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}